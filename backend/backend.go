@@ -0,0 +1,98 @@
+// Package backend defines the sandbox operations seven drives. The sprite CLI
+// is the default implementation; other sandboxes plug in behind the same
+// interface, and tests can substitute an in-process double.
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// File is a host file uploaded into the sandbox before a command runs.
+type File struct {
+	Local  string // path on the host
+	Remote string // absolute path inside the sandbox
+}
+
+// ExecRequest describes one command run inside a sandbox. Command is an argv,
+// not a shell string; shell programs are run as "sh", "-lc", script.
+type ExecRequest struct {
+	Command []string
+	Env     []string // KEY=VALUE pairs set for the command
+	Files   []File   // uploaded before the command starts
+	Stdin   io.Reader
+	Stdout  io.Writer // nil discards
+	Stderr  io.Writer // nil discards
+	// LongRunning asks for a transport that survives long, quiet commands
+	// (the sprite CLI's HTTP POST mode). Backends without one ignore it.
+	LongRunning bool
+}
+
+// Checkpoint is one disk snapshot of a sandbox.
+type Checkpoint struct {
+	ID      string
+	Created time.Time // zero when the backend does not report it
+	Comment string
+	Line    string // the backend's row for it (creation time, comment), verbatim
+}
+
+// Backend creates, lists, and runs commands in named sandboxes. Cancelling a
+// method's ctx stops whatever host process it started; the sandbox is left as
+// the interrupted command left it.
+type Backend interface {
+	// Name identifies the backend in config files and log lines.
+	Name() string
+	Create(ctx context.Context, name string) error
+	Destroy(ctx context.Context, name string) error
+	// List returns the names of every sandbox the backend can see.
+	List(ctx context.Context) ([]string, error)
+	// Exec runs a command with the request's stdio. A non-zero exit is
+	// reported as an error whose exit status ExitCode recovers.
+	Exec(ctx context.Context, name string, req ExecRequest) error
+	// ExecOutput runs a command and returns its combined stdout and stderr
+	// with surrounding whitespace trimmed; req.Stdout and req.Stderr are
+	// ignored.
+	ExecOutput(ctx context.Context, name string, req ExecRequest) (string, error)
+	CopyFile(ctx context.Context, name, local, remote string) error
+	// Console attaches the terminal to an interactive shell.
+	Console(ctx context.Context, name string) error
+	Checkpointer
+}
+
+// Checkpointer snapshots and restores a sandbox's disk.
+type Checkpointer interface {
+	// CreateCheckpoint returns the backend's confirmation message.
+	CreateCheckpoint(ctx context.Context, name, comment string) (string, error)
+	// ListCheckpoints returns checkpoints oldest first.
+	ListCheckpoints(ctx context.Context, name string) ([]Checkpoint, error)
+	RestoreCheckpoint(ctx context.Context, name, id string) error
+	DeleteCheckpoint(ctx context.Context, name, id string) (string, error)
+}
+
+// Authenticator is implemented by backends that need an interactive login
+// before first use.
+type Authenticator interface {
+	Login(ctx context.Context) error
+}
+
+// PortForwarder is implemented by backends that can forward a local TCP port
+// to a port inside the sandbox. Forward blocks until the forward ends or ctx
+// is cancelled.
+type PortForwarder interface {
+	Forward(ctx context.Context, name string, local, remote int) error
+}
+
+// ErrUnsupported is returned for operations a backend cannot perform.
+var ErrUnsupported = errors.New("not supported by this backend")
+
+// ExitCode returns the exit status carried by an Exec error: an
+// *exec.ExitError, or any error in the chain with an ExitCode method.
+func ExitCode(err error) (int, bool) {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,520 @@
+// Package fake is an in-process backend.Backend for tests. It keeps sprites in
+// memory, records every call with its structured arguments (argv, env, and
+// the contents of uploaded files), and answers exec calls from programmable
+// rules, so a test can assert on provisioning precisely and inject failures
+// such as a lost exit frame without a shell-script stand-in for the sprite CLI.
+//
+//	b := fake.New()
+//	b.OnExec(fake.CommandContains("garrytan/gstack"), fake.NoExitFrame("setup done"))
+//	// ... run the code under test against b ...
+//	for _, call := range b.Execs() { ... }
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"seven/backend"
+)
+
+// ErrNotFound is returned for operations on a sprite that does not exist.
+var ErrNotFound = errors.New("sprite not found")
+
+// ExitError is the error an exec call returns for a non-zero exit status.
+// backend.ExitCode recovers the status from it.
+type ExitError struct{ Code int }
+
+func (e *ExitError) Error() string { return fmt.Sprintf("exit status %d", e.Code) }
+func (e *ExitError) ExitCode() int { return e.Code }
+
+// File is one uploaded file as the sprite received it. Contents is read when
+// the call is made, so it survives the caller deleting its temporary file.
+type File struct {
+	Local    string
+	Remote   string
+	Contents []byte
+	Mode     os.FileMode
+}
+
+// ExecCall records one Exec or ExecOutput call.
+type ExecCall struct {
+	Sprite      string
+	Command     []string
+	Env         []string
+	Files       []File
+	Stdin       []byte // nil when there was no stdin or it was a terminal
+	LongRunning bool
+	Output      bool // true for ExecOutput
+}
+
+// String renders the command as a space-joined argv, for matching and
+// failure messages.
+func (c ExecCall) String() string { return strings.Join(c.Command, " ") }
+
+// Script returns the program of an `sh -c`/`sh -lc` call, or "" for any
+// other command.
+func (c ExecCall) Script() string {
+	if len(c.Command) >= 3 && (c.Command[0] == "sh" || c.Command[0] == "bash") && strings.HasPrefix(c.Command[1], "-") && strings.Contains(c.Command[1], "c") {
+		return c.Command[2]
+	}
+	return ""
+}
+
+// EnvValue returns the value the call set for key.
+func (c ExecCall) EnvValue(key string) (string, bool) {
+	for _, kv := range c.Env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// File returns the file uploaded to remote.
+func (c ExecCall) File(remote string) (File, bool) {
+	for _, f := range c.Files {
+		if f.Remote == remote {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Call records one backend operation in order. Op is the method name in
+// lower case ("create", "exec", "checkpoint-create", ...); Exec is set for
+// exec calls only.
+type Call struct {
+	Op     string
+	Sprite string
+	Args   []string
+	Exec   *ExecCall
+}
+
+// Reply is what a matched exec call produces.
+type Reply struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int   // non-zero fails the call with an *ExitError
+	Err      error // returned as is (after the output is written), e.g. a transport error
+}
+
+// NoExitFrame reproduces the sprite CLI losing the exit frame of a long
+// command: the output arrives, then the call fails with the CLI's message.
+func NoExitFrame(stdout string) Reply {
+	return Reply{Stdout: stdout, Stderr: "Error: no exit frame received\n", ExitCode: 1}
+}
+
+// Rule answers the exec calls Match accepts. Times limits how many calls it
+// answers; zero means every call.
+type Rule struct {
+	Match func(ExecCall) bool
+	Reply Reply
+	Times int
+	used  int
+}
+
+// CommandContains matches calls whose joined argv contains substr.
+func CommandContains(substr string) func(ExecCall) bool {
+	return func(c ExecCall) bool { return strings.Contains(c.String(), substr) }
+}
+
+// Sprite is the in-memory state of one sandbox.
+type Sprite struct {
+	Name        string
+	Files       map[string]File // every file uploaded, by remote path
+	Checkpoints []backend.Checkpoint
+}
+
+// Backend is the in-memory fake. The zero value is not usable; call New.
+type Backend struct {
+	mu       sync.Mutex
+	sprites  map[string]*Sprite
+	order    []string
+	calls    []Call
+	rules    []*Rule
+	failures map[string][]error
+	nextID   int
+}
+
+var (
+	_ backend.Backend       = (*Backend)(nil)
+	_ backend.Authenticator = (*Backend)(nil)
+	_ backend.PortForwarder = (*Backend)(nil)
+)
+
+// New returns a fake with no sprites. Exec calls succeed with no output until
+// rules say otherwise.
+func New() *Backend {
+	return &Backend{sprites: map[string]*Sprite{}, failures: map[string][]error{}}
+}
+
+// AddSprite creates sprites without recording a call, for tests that start
+// from an existing sandbox.
+func (b *Backend) AddSprite(names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, name := range names {
+		b.addLocked(name)
+	}
+}
+
+func (b *Backend) addLocked(name string) {
+	if _, ok := b.sprites[name]; ok {
+		return
+	}
+	b.sprites[name] = &Sprite{Name: name, Files: map[string]File{}}
+	b.order = append(b.order, name)
+}
+
+// OnExec adds a rule answering every matching exec call. Rules are tried in
+// the order they were added; the first match wins.
+func (b *Backend) OnExec(match func(ExecCall) bool, reply Reply) *Rule {
+	return b.addRule(&Rule{Match: match, Reply: reply})
+}
+
+// OnExecOnce adds a rule answering only the next matching exec call.
+func (b *Backend) OnExecOnce(match func(ExecCall) bool, reply Reply) *Rule {
+	return b.addRule(&Rule{Match: match, Reply: reply, Times: 1})
+}
+
+func (b *Backend) addRule(rule *Rule) *Rule {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rules = append(b.rules, rule)
+	return rule
+}
+
+// FailNext makes the next call of op fail with err. Ops are the Call.Op
+// names; queued failures are consumed in order.
+func (b *Backend) FailNext(op string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[op] = append(b.failures[op], err)
+}
+
+// Calls returns every operation so far, in order.
+func (b *Backend) Calls() []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.calls)
+}
+
+// Ops returns each call as "op sprite", e.g. "create hello", which reads well
+// in test failure messages.
+func (b *Backend) Ops() []string {
+	var ops []string
+	for _, call := range b.Calls() {
+		ops = append(ops, strings.TrimSpace(call.Op+" "+call.Sprite))
+	}
+	return ops
+}
+
+// Execs returns the recorded exec calls, in order.
+func (b *Backend) Execs() []ExecCall {
+	var execs []ExecCall
+	for _, call := range b.Calls() {
+		if call.Exec != nil {
+			execs = append(execs, *call.Exec)
+		}
+	}
+	return execs
+}
+
+// FindExec returns the first exec call whose argv contains substr.
+func (b *Backend) FindExec(substr string) (ExecCall, bool) {
+	for _, call := range b.Execs() {
+		if strings.Contains(call.String(), substr) {
+			return call, true
+		}
+	}
+	return ExecCall{}, false
+}
+
+// Sprite returns a copy of a sprite's state.
+func (b *Backend) Sprite(name string) (Sprite, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return Sprite{}, false
+	}
+	copied := Sprite{Name: s.Name, Files: map[string]File{}, Checkpoints: slices.Clone(s.Checkpoints)}
+	for k, v := range s.Files {
+		copied.Files[k] = v
+	}
+	return copied, true
+}
+
+// record appends a call and returns the failure queued for its op, if any.
+// A call whose ctx is already cancelled fails with ctx's error and is not
+// recorded: a real backend would never have reached the sandbox.
+func (b *Backend) record(ctx context.Context, call Call) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, call)
+	if queued := b.failures[call.Op]; len(queued) > 0 {
+		b.failures[call.Op] = queued[1:]
+		return queued[0]
+	}
+	return nil
+}
+
+func (b *Backend) exists(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.sprites[name]
+	return ok
+}
+
+func (b *Backend) Name() string { return "fake" }
+
+func (b *Backend) Login(ctx context.Context) error { return b.record(ctx, Call{Op: "login"}) }
+
+func (b *Backend) Create(ctx context.Context, name string) error {
+	if err := b.record(ctx, Call{Op: "create", Sprite: name}); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.sprites[name]; ok {
+		return fmt.Errorf("sprite %s already exists", name)
+	}
+	b.addLocked(name)
+	return nil
+}
+
+func (b *Backend) Destroy(ctx context.Context, name string) error {
+	if err := b.record(ctx, Call{Op: "destroy", Sprite: name}); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.sprites[name]; !ok {
+		return fmt.Errorf("destroy %s: %w", name, ErrNotFound)
+	}
+	delete(b.sprites, name)
+	b.order = slices.DeleteFunc(b.order, func(n string) bool { return n == name })
+	return nil
+}
+
+func (b *Backend) List(ctx context.Context) ([]string, error) {
+	if err := b.record(ctx, Call{Op: "list"}); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.order), nil
+}
+
+func (b *Backend) Exec(ctx context.Context, name string, req backend.ExecRequest) error {
+	reply, err := b.exec(ctx, name, req, false)
+	if err != nil {
+		return err
+	}
+	if req.Stdout != nil {
+		io.WriteString(req.Stdout, reply.Stdout)
+	}
+	if req.Stderr != nil {
+		io.WriteString(req.Stderr, reply.Stderr)
+	}
+	return replyErr(reply)
+}
+
+func (b *Backend) ExecOutput(ctx context.Context, name string, req backend.ExecRequest) (string, error) {
+	reply, err := b.exec(ctx, name, req, true)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(reply.Stdout + reply.Stderr), replyErr(reply)
+}
+
+func replyErr(reply Reply) error {
+	if reply.Err != nil {
+		return reply.Err
+	}
+	if reply.ExitCode != 0 {
+		return &ExitError{Code: reply.ExitCode}
+	}
+	return nil
+}
+
+// exec records the call, stores its uploads on the sprite, and picks the
+// reply of the first matching rule. A rule's Match may cancel ctx to simulate
+// an interrupt arriving while the command runs; the call then fails with
+// ctx's error instead of the rule's reply.
+func (b *Backend) exec(ctx context.Context, name string, req backend.ExecRequest, output bool) (Reply, error) {
+	call := ExecCall{
+		Sprite:      name,
+		Command:     slices.Clone(req.Command),
+		Env:         slices.Clone(req.Env),
+		LongRunning: req.LongRunning,
+		Output:      output,
+	}
+	for _, f := range req.Files {
+		uploaded, err := readUpload(f)
+		if err != nil {
+			return Reply{}, err
+		}
+		call.Files = append(call.Files, uploaded)
+	}
+	// Never block on a terminal; seven attaches os.Stdin to interactive execs.
+	if _, isFile := req.Stdin.(*os.File); req.Stdin != nil && !isFile {
+		data, err := io.ReadAll(req.Stdin)
+		if err != nil {
+			return Reply{}, err
+		}
+		call.Stdin = data
+	}
+	if err := b.record(ctx, Call{Op: "exec", Sprite: name, Args: call.Command, Exec: &call}); err != nil {
+		return Reply{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return Reply{}, fmt.Errorf("exec in %s: %w", name, ErrNotFound)
+	}
+	for _, f := range call.Files {
+		s.Files[f.Remote] = f
+	}
+	for _, rule := range b.rules {
+		if rule.Times > 0 && rule.used >= rule.Times {
+			continue
+		}
+		if rule.Match(call) {
+			rule.used++
+			if err := ctx.Err(); err != nil {
+				return Reply{}, err
+			}
+			return rule.Reply, nil
+		}
+	}
+	return Reply{}, nil
+}
+
+func readUpload(f backend.File) (File, error) {
+	info, err := os.Stat(f.Local)
+	if err != nil {
+		return File{}, err
+	}
+	data, err := os.ReadFile(f.Local)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Local: f.Local, Remote: f.Remote, Contents: data, Mode: info.Mode().Perm()}, nil
+}
+
+func (b *Backend) CopyFile(ctx context.Context, name, local, remote string) error {
+	uploaded, err := readUpload(backend.File{Local: local, Remote: remote})
+	if err != nil {
+		return err
+	}
+	if err := b.record(ctx, Call{Op: "copy", Sprite: name, Args: []string{local, remote}}); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return fmt.Errorf("copy to %s: %w", name, ErrNotFound)
+	}
+	s.Files[remote] = uploaded
+	return nil
+}
+
+func (b *Backend) Console(ctx context.Context, name string) error {
+	if err := b.record(ctx, Call{Op: "console", Sprite: name}); err != nil {
+		return err
+	}
+	if !b.exists(name) {
+		return fmt.Errorf("console %s: %w", name, ErrNotFound)
+	}
+	return nil
+}
+
+func (b *Backend) CreateCheckpoint(ctx context.Context, name, comment string) (string, error) {
+	if err := b.record(ctx, Call{Op: "checkpoint-create", Sprite: name, Args: []string{comment}}); err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return "", fmt.Errorf("checkpoint %s: %w", name, ErrNotFound)
+	}
+	b.nextID++
+	id := fmt.Sprintf("v%d", b.nextID)
+	s.Checkpoints = append(s.Checkpoints, backend.Checkpoint{ID: id, Created: time.Now(), Comment: comment, Line: id + " " + comment})
+	return "Created checkpoint " + id, nil
+}
+
+func (b *Backend) ListCheckpoints(ctx context.Context, name string) ([]backend.Checkpoint, error) {
+	if err := b.record(ctx, Call{Op: "checkpoint-list", Sprite: name}); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return nil, fmt.Errorf("checkpoint list %s: %w", name, ErrNotFound)
+	}
+	return slices.Clone(s.Checkpoints), nil
+}
+
+func (b *Backend) RestoreCheckpoint(ctx context.Context, name, id string) error {
+	if err := b.record(ctx, Call{Op: "checkpoint-restore", Sprite: name, Args: []string{id}}); err != nil {
+		return err
+	}
+	_, err := b.checkpointIndex(name, id)
+	return err
+}
+
+func (b *Backend) DeleteCheckpoint(ctx context.Context, name, id string) (string, error) {
+	if err := b.record(ctx, Call{Op: "checkpoint-delete", Sprite: name, Args: []string{id}}); err != nil {
+		return "", err
+	}
+	i, err := b.checkpointIndex(name, id)
+	if err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.sprites[name]
+	s.Checkpoints = slices.Delete(s.Checkpoints, i, i+1)
+	return "Deleted checkpoint " + id, nil
+}
+
+func (b *Backend) checkpointIndex(name, id string) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s, ok := b.sprites[name]
+	if !ok {
+		return 0, fmt.Errorf("checkpoint %s: %w", name, ErrNotFound)
+	}
+	i := slices.IndexFunc(s.Checkpoints, func(c backend.Checkpoint) bool { return c.ID == id })
+	if i < 0 {
+		return 0, fmt.Errorf("checkpoint %s in %s: %w", id, name, ErrNotFound)
+	}
+	return i, nil
+}
+
+// Forward records the forward and blocks until ctx is cancelled, like a
+// healthy proxy.
+func (b *Backend) Forward(ctx context.Context, name string, local, remote int) error {
+	if err := b.record(ctx, Call{Op: "forward", Sprite: name, Args: []string{fmt.Sprint(local), fmt.Sprint(remote)}}); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
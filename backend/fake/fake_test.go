@@ -0,0 +1,140 @@
+package fake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"seven/backend"
+)
+
+func TestExecRecordsRequestsAndUploads(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	b.AddSprite("hello")
+	local := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(local, []byte(`{"token":"x"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	b.OnExec(CommandContains("cat"), Reply{Stdout: "ok\n"})
+	err := b.Exec(ctx, "hello", backend.ExecRequest{
+		Command: []string{"sh", "-lc", "cat /tmp/auth.json"},
+		Env:     []string{"LIST=a,b"},
+		Files:   []backend.File{{Local: local, Remote: "/tmp/auth.json"}},
+		Stdin:   strings.NewReader("input"),
+		Stdout:  &stdout,
+	})
+	if err != nil || stdout.String() != "ok\n" {
+		t.Fatalf("unexpected exec result %v, stdout %q", err, stdout.String())
+	}
+	if err := os.Remove(local); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := b.Execs()
+	if len(calls) != 1 {
+		t.Fatalf("expected one exec, got %d", len(calls))
+	}
+	call := calls[0]
+	if call.Script() != "cat /tmp/auth.json" || string(call.Stdin) != "input" {
+		t.Fatalf("unexpected recorded call: %+v", call)
+	}
+	if v, ok := call.EnvValue("LIST"); !ok || v != "a,b" {
+		t.Fatalf("expected env LIST=a,b, got %q", v)
+	}
+	if f, ok := call.File("/tmp/auth.json"); !ok || string(f.Contents) != `{"token":"x"}` || f.Mode != 0o600 {
+		t.Fatalf("expected the upload's contents and mode to be kept, got %+v", f)
+	}
+	if s, _ := b.Sprite("hello"); string(s.Files["/tmp/auth.json"].Contents) != `{"token":"x"}` {
+		t.Fatalf("expected the upload on the sprite, got %+v", s.Files)
+	}
+}
+
+func TestRulesAndFailures(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	if err := b.Create(ctx, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	b.OnExecOnce(CommandContains("setup"), NoExitFrame("done"))
+	b.OnExec(CommandContains("setup"), Reply{Stdout: "second"})
+
+	out, err := b.ExecOutput(ctx, "hello", backend.ExecRequest{Command: []string{"./setup"}})
+	if code, ok := backend.ExitCode(err); !ok || code != 1 || !strings.Contains(out, "Error: no exit frame received") {
+		t.Fatalf("expected a lost exit frame, got %q %v", out, err)
+	}
+	if out, err := b.ExecOutput(ctx, "hello", backend.ExecRequest{Command: []string{"./setup"}}); err != nil || out != "second" {
+		t.Fatalf("expected the once rule to be spent, got %q %v", out, err)
+	}
+
+	boom := errors.New("boom")
+	b.FailNext("destroy", boom)
+	if err := b.Destroy(ctx, "hello"); !errors.Is(err, boom) {
+		t.Fatalf("expected the queued failure, got %v", err)
+	}
+	if err := b.Destroy(ctx, "hello"); err != nil {
+		t.Fatalf("expected the second destroy to succeed: %v", err)
+	}
+	if err := b.Exec(ctx, "hello", backend.ExecRequest{Command: []string{"true"}}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected exec in a destroyed sprite to fail, got %v", err)
+	}
+	want := []string{"create hello", "exec hello", "exec hello", "destroy hello", "destroy hello", "exec hello"}
+	if ops := b.Ops(); !slices.Equal(ops, want) {
+		t.Fatalf("unexpected ops:\n got %q\nwant %q", ops, want)
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	b := New()
+	b.AddSprite("hello")
+	for _, comment := range []string{"first", "second"} {
+		if _, err := b.CreateCheckpoint(ctx, "hello", comment); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := b.DeleteCheckpoint(ctx, "hello", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	list, err := b.ListCheckpoints(ctx, "hello")
+	if err != nil || len(list) != 1 || list[0].ID != "v2" || list[0].Line != "v2 second" {
+		t.Fatalf("unexpected checkpoints %+v %v", list, err)
+	}
+	if err := b.RestoreCheckpoint(ctx, "hello", "v1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected restoring a deleted checkpoint to fail, got %v", err)
+	}
+}
+
+func TestCancelledCalls(t *testing.T) {
+	b := New()
+	b.AddSprite("hello")
+	ctx, cancel := context.WithCancel(context.Background())
+	b.OnExec(func(c ExecCall) bool {
+		if strings.Contains(c.String(), "clone") {
+			cancel()
+			return true
+		}
+		return false
+	}, Reply{Stdout: "cloned"})
+
+	if err := b.Exec(ctx, "hello", backend.ExecRequest{Command: []string{"gh", "repo", "clone"}}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the interrupted exec to fail with the cancellation, got %v", err)
+	}
+	if err := b.Exec(ctx, "hello", backend.ExecRequest{Command: []string{"true"}}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected an exec after cancellation to fail, got %v", err)
+	}
+	if err := b.Destroy(context.Background(), "hello"); err != nil {
+		t.Fatalf("expected cleanup with a fresh context to work: %v", err)
+	}
+	want := []string{"exec hello", "destroy hello"}
+	if ops := b.Ops(); !slices.Equal(ops, want) {
+		t.Fatalf("unexpected ops:\n got %q\nwant %q", ops, want)
+	}
+}
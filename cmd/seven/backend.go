@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"seven/backend"
+)
+
+// backendNames lists the backends a user profile may select with backend = "...".
+var backendNames = []string{"sprite", "docker", "podman"}
+
+// activeBackend is the sandbox provider every command drives. main replaces
+// it when the user profile selects another backend.
+var activeBackend backend.Backend = spriteCLIBackend{}
+
+func newBackend(settings userSettings) (backend.Backend, error) {
+	switch settings.Backend {
+	case "", "sprite":
+		return spriteCLIBackend{}, nil
+	case "docker", "podman":
+		return newContainerBackend(settings.Backend, settings.ContainerImage), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q (use %s)", settings.Backend, strings.Join(backendNames, ", "))
+	}
+}
+
+// usesSpriteCLI reports whether the active backend needs the sprite CLI
+// installed, logged in, and kept up to date.
+func usesSpriteCLI() bool {
+	_, ok := activeBackend.(spriteCLIBackend)
+	return ok
+}
+
+// prepareBackend checks the active backend's host-side prerequisites,
+// installing the sprite CLI when it is missing.
+func prepareBackend() error {
+	if p, ok := activeBackend.(interface{ prepare() error }); ok {
+		return p.prepare()
+	}
+	return nil
+}
+
+// loginBackend runs the active backend's interactive login, if it has one.
+func loginBackend(ctx context.Context) error {
+	if auth, ok := activeBackend.(backend.Authenticator); ok {
+		return auth.Login(ctx)
+	}
+	return nil
+}
+
+// spriteCLIBackend drives Fly Sprites through the sprite CLI. Every call goes
+// through spriteArgs so it targets the user profile's org.
+type spriteCLIBackend struct{}
+
+func (spriteCLIBackend) Name() string { return "sprite" }
+
+func (spriteCLIBackend) prepare() error { return ensureSpriteCLI() }
+
+func (spriteCLIBackend) Login(ctx context.Context) error {
+	return runCmd(ctx, spriteBin(), nil, "login")
+}
+
+// Create captures the CLI's output and returns its tail on failure, so
+// progress chatter does not interleave with seven's own log lines.
+func (spriteCLIBackend) Create(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	out, err := runCmdOutput(ctx, spriteBin(), nil, spriteArgs("create", "--skip-console", name)...)
+	if err != nil {
+		return fmt.Errorf("sprite create %s: %w%s", name, err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+func (spriteCLIBackend) Destroy(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	return runCmd(ctx, spriteBin(), nil, spriteArgs("destroy", "--force", name)...)
+}
+
+// List parses `sprite list`, whose table has no stable machine-readable
+// form: every token shaped like a sprite name counts as one.
+func (spriteCLIBackend) List(ctx context.Context) ([]string, error) {
+	out, err := runCmdOutput(ctx, spriteBin(), nil, spriteArgs("list")...)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, field := range strings.Fields(ansiEscapeRe.ReplaceAllString(out, "")) {
+		if !seen[field] && spriteNamePattern.MatchString(field) {
+			seen[field] = true
+			names = append(names, field)
+		}
+	}
+	return names, nil
+}
+
+func (b spriteCLIBackend) Exec(ctx context.Context, name string, req backend.ExecRequest) error {
+	args, err := b.execArgs(name, req)
+	if err != nil {
+		return err
+	}
+	cmd := commandContext(ctx, spriteBin(), spriteArgs(args...)...)
+	cmd.Stdin = req.Stdin
+	cmd.Stdout = req.Stdout
+	cmd.Stderr = req.Stderr
+	return cmd.Run()
+}
+
+func (b spriteCLIBackend) ExecOutput(ctx context.Context, name string, req backend.ExecRequest) (string, error) {
+	args, err := b.execArgs(name, req)
+	if err != nil {
+		return "", err
+	}
+	if req.Stdin == nil {
+		return runCmdOutput(ctx, spriteBin(), nil, spriteArgs(args...)...)
+	}
+	cmd := commandContext(ctx, spriteBin(), spriteArgs(args...)...)
+	cmd.Stdin = req.Stdin
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// execArgs builds `sprite exec`'s arguments. The CLI takes one -env flag
+// holding a comma-separated list, so values cannot themselves contain commas.
+func (spriteCLIBackend) execArgs(name string, req backend.ExecRequest) ([]string, error) {
+	if err := requireSpriteName(name); err != nil {
+		return nil, err
+	}
+	args := []string{"exec", "-s", name}
+	if req.LongRunning {
+		args = append(args, "--http-post")
+	}
+	for _, kv := range req.Env {
+		if key, value, _ := strings.Cut(kv, "="); strings.Contains(value, ",") {
+			return nil, fmt.Errorf("sprite exec cannot pass %s: its value contains a comma", key)
+		}
+	}
+	if len(req.Env) > 0 {
+		args = append(args, "-env", strings.Join(req.Env, ","))
+	}
+	for _, file := range req.Files {
+		args = append(args, "-file", file.Local+":"+file.Remote)
+	}
+	args = append(args, "--")
+	return append(args, req.Command...), nil
+}
+
+func (b spriteCLIBackend) CopyFile(ctx context.Context, name, local, remote string) error {
+	out, err := b.ExecOutput(ctx, name, backend.ExecRequest{
+		Command: []string{"true"},
+		Files:   []backend.File{{Local: local, Remote: remote}},
+	})
+	if err != nil {
+		return fmt.Errorf("copy %s to %s: %w%s", local, remote, err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+func (spriteCLIBackend) Console(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	return runCmd(ctx, spriteBin(), nil, spriteArgs("console", "-s", name)...)
+}
+
+func (spriteCLIBackend) CreateCheckpoint(ctx context.Context, name, comment string) (string, error) {
+	if err := requireSpriteName(name); err != nil {
+		return "", err
+	}
+	return runCmdOutput(ctx, spriteBin(), nil, spriteArgs("checkpoint", "create", "-s", name, "--comment", comment)...)
+}
+
+func (spriteCLIBackend) ListCheckpoints(ctx context.Context, name string) ([]backend.Checkpoint, error) {
+	if err := requireSpriteName(name); err != nil {
+		return nil, err
+	}
+	out, err := runCmdOutput(ctx, spriteBin(), nil, spriteArgs("checkpoint", "list", "-s", name)...)
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", err, gstackOutputTail(out))
+	}
+	return parseCheckpointList(out), nil
+}
+
+func (spriteCLIBackend) RestoreCheckpoint(ctx context.Context, name, id string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	return runCmd(ctx, spriteBin(), nil, spriteArgs("restore", "-s", name, id)...)
+}
+
+func (spriteCLIBackend) DeleteCheckpoint(ctx context.Context, name, id string) (string, error) {
+	if err := requireSpriteName(name); err != nil {
+		return "", err
+	}
+	return runCmdOutput(ctx, spriteBin(), nil, spriteArgs("checkpoint", "delete", "-s", name, id)...)
+}
+
+// Forward runs `sprite proxy` in the foreground until it exits or ctx is
+// cancelled.
+func (spriteCLIBackend) Forward(ctx context.Context, name string, local, remote int) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	spec := devPort{Local: local, Remote: remote}.spec()
+	cmd := commandContext(ctx, spriteBin(), spriteArgs("proxy", "-s", name, spec)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func requireSpriteName(name string) error {
+	if name == "" {
+		return errors.New("sprite name is empty")
+	}
+	return nil
+}
+
+// withStdio attaches seven's terminal to req, or discards the command's
+// output when quiet is set.
+func withStdio(req backend.ExecRequest, quiet bool) backend.ExecRequest {
+	if !quiet {
+		req.Stdin, req.Stdout, req.Stderr = os.Stdin, os.Stdout, os.Stderr
+	} else {
+		req.Stdout, req.Stderr = io.Discard, io.Discard
+	}
+	return req
+}
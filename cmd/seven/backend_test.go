@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"seven/backend"
+	"seven/backend/fake"
+)
+
+func TestSpriteCLIBackendExecArgs(t *testing.T) {
+	args, err := spriteCLIBackend{}.execArgs("hello", backend.ExecRequest{
+		Command:     []string{"sh", "-lc", "true"},
+		Env:         []string{"SEVEN_REPO_DIR=hello", "SEVEN_ASSISTANT=codex"},
+		Files:       []backend.File{{Local: "/tmp/a.json", Remote: "/tmp/b.json"}},
+		LongRunning: true,
+	})
+	if err != nil {
+		t.Fatalf("execArgs failed: %v", err)
+	}
+	want := []string{"exec", "-s", "hello", "--http-post", "-env", "SEVEN_REPO_DIR=hello,SEVEN_ASSISTANT=codex", "-file", "/tmp/a.json:/tmp/b.json", "--", "sh", "-lc", "true"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("unexpected sprite exec args:\n got %q\nwant %q", args, want)
+	}
+
+	if _, err := (spriteCLIBackend{}).execArgs("hello", backend.ExecRequest{Command: []string{"true"}, Env: []string{"LIST=a,b"}}); err == nil || !strings.Contains(err.Error(), "LIST") {
+		t.Fatalf("expected a comma in an -env value to be rejected, got %v", err)
+	}
+	if _, err := (spriteCLIBackend{}).execArgs("", backend.ExecRequest{Command: []string{"true"}}); err == nil {
+		t.Fatal("expected an empty sprite name to be rejected")
+	}
+}
+
+func TestSpriteCLIBackendListParsesTable(t *testing.T) {
+	repo := t.TempDir()
+	state, _, cleanup := createFakeSprite(t)
+	defer cleanup()
+	if err := os.WriteFile(state, []byte("NAME\n\x1b[1mseven\x1b[0m\nseven-02\nseven\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+	t.Setenv("PATH", filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SPRITE_STATE", state)
+
+	names, err := spriteCLIBackend{}.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"seven", "seven-02"}) {
+		t.Fatalf("unexpected names: %q", names)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if _, ok := backend.ExitCode(nil); ok {
+		t.Fatal("nil error has no exit code")
+	}
+	if code, ok := backend.ExitCode(exitStatus(3)); !ok || code != 3 {
+		t.Fatalf("expected exit code 3, got %d %v", code, ok)
+	}
+}
+
+type exitStatus int
+
+func (e exitStatus) Error() string { return "exit status" }
+func (e exitStatus) ExitCode() int { return int(e) }
+
+// useFakeBackend makes an in-process fake the active backend for one test.
+// Tests using it must not run in parallel.
+func useFakeBackend(t *testing.T) *fake.Backend {
+	t.Helper()
+	previous := activeBackend
+	b := fake.New()
+	activeBackend = b
+	t.Cleanup(func() { activeBackend = previous })
+	return b
+}
+
+// isolateHostForInit keeps runInit from picking up the developer's own gh
+// token and assistant credentials.
+func isolateHostForInit(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+}
+
+func TestRunInitWithFakeBackend(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := useFakeBackend(t)
+	b.OnExec(fake.CommandContains("printf 'present'"), fake.Reply{Stdout: "absent"})
+
+	res, err := runInit(context.Background(), upOptions{AssumeLoggedIn: true, QuietExternal: true})
+	if err != nil {
+		t.Fatalf("runInit failed: %v\nops: %q", err, b.Ops())
+	}
+	if res.Name != "hello" || res.SpriteExists {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if ops := b.Ops(); !slices.Equal(ops[:2], []string{"list", "create hello"}) || slices.Contains(ops, "login") {
+		t.Fatalf("expected a list, then create, and no login, got %q", ops)
+	}
+	clone, ok := b.FindExec("gh repo clone")
+	if !ok || !slices.Equal(clone.Command, []string{"gh", "repo", "clone", "octo/hello", "hello"}) || len(clone.Env) != 0 {
+		t.Fatalf("expected a tokenless gh clone into hello, got %+v (found %v)", clone, ok)
+	}
+	bootstrap, ok := b.FindExec(sevenConsoleHookPath)
+	if repoDir, _ := bootstrap.EnvValue("SEVEN_REPO_DIR"); !ok || repoDir != "hello" {
+		t.Fatalf("expected the console bootstrap to target hello, got %+v", bootstrap)
+	}
+}
+
+func TestRunInitDestroysSpriteWhenCloneFails(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := useFakeBackend(t)
+	b.OnExec(fake.CommandContains("gh repo clone"), fake.Reply{Stderr: "repository not found", ExitCode: 1})
+
+	if _, err := runInit(context.Background(), upOptions{AssumeLoggedIn: true, QuietExternal: true, DestroyOnFailure: true}); err == nil {
+		t.Fatal("expected runInit to fail when the clone fails")
+	}
+	if ops := b.Ops(); ops[len(ops)-1] != "destroy hello" {
+		t.Fatalf("expected the incomplete sprite to be destroyed last, got %q", ops)
+	}
+	if _, ok := b.Sprite("hello"); ok {
+		t.Fatal("expected the sprite to be gone")
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, ".sprite")); string(data) != "hello\n" {
+		t.Fatalf("expected the previous .sprite selection to be restored, got %q", data)
+	}
+}
+
+func TestRunInitKeepsFailedSpriteAndResumes(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "config", "user.email", "dev@example.com").CombinedOutput(); err != nil {
+		t.Fatalf("git config failed: %v\n%s", err, out)
+	}
+	b := useFakeBackend(t)
+	b.OnExecOnce(fake.CommandContains("gh repo clone"), fake.Reply{Stderr: "connection reset", ExitCode: 1})
+	b.OnExec(fake.CommandContains("printf 'present'"), fake.Reply{Stdout: "absent"})
+
+	opts := upOptions{AssumeLoggedIn: true, QuietExternal: true}
+	if _, err := runInit(context.Background(), opts); err == nil {
+		t.Fatal("expected runInit to fail when the clone fails")
+	}
+	if _, ok := b.Sprite("hello"); !ok || slices.Contains(b.Ops(), "destroy hello") {
+		t.Fatalf("expected the failed sprite to be kept, got %q", b.Ops())
+	}
+	execs := b.Execs()
+	marker := execs[len(execs)-1]
+	if !strings.Contains(marker.Script(), sevenInitProgressPath) {
+		t.Fatalf("expected the failure to record progress last, got %q", marker.Command)
+	}
+	want := []string{initStepCreated, initStepIdentity, initStepGhAuth, initStepAssistantSync}
+	if got := marker.Command[4:]; !slices.Equal(got, want) {
+		t.Fatalf("unexpected recorded steps: %q", got)
+	}
+
+	// The sprite now reports that progress back.
+	b.OnExec(fake.CommandContains(`cat "`+sevenInitProgressPath), fake.Reply{Stdout: strings.Join(want, "\n")})
+	if _, err := runInit(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "seven up --resume") {
+		t.Fatalf("expected an unfinished sprite to be refused without --resume, got %v", err)
+	}
+
+	before := len(b.Execs())
+	opts.Resume = true
+	if _, err := runInit(context.Background(), opts); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	resumed := b.Execs()[before:]
+	var scripts []string
+	for _, call := range resumed {
+		scripts = append(scripts, call.String())
+	}
+	joined := strings.Join(scripts, "\n")
+	if _, ok := b.FindExec("user.email dev@example.com"); !ok || strings.Contains(joined, "user.email") {
+		t.Fatalf("expected the identity step to run once and be skipped on resume, got:\n%s", joined)
+	}
+	for _, want := range []string{`rm -rf "$HOME/$1" sh hello`, "gh repo clone octo/hello hello", sevenConsoleHookPath, `rm -f "` + sevenInitProgressPath} {
+		if !strings.Contains(joined, want) {
+			t.Fatalf("expected %q in the resumed run, got:\n%s", want, joined)
+		}
+	}
+	if ops := b.Ops(); slices.Contains(ops[2:], "create hello") {
+		t.Fatalf("expected resume not to create the sprite again, got %q", ops)
+	}
+}
+
+func TestRunInitInterruptedDuringCloneKeepsSprite(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := useFakeBackend(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.OnExec(func(c fake.ExecCall) bool {
+		if strings.Contains(c.String(), "gh repo clone") {
+			cancel()
+			return true
+		}
+		return false
+	}, fake.Reply{})
+
+	var logs []string
+	opts := upOptions{AssumeLoggedIn: true, QuietExternal: true, Logger: func(msg string) { logs = append(logs, msg) }}
+	_, err := runInit(ctx, opts)
+	if !errors.Is(err, errInterrupted) || !strings.Contains(err.Error(), "after init step assistant-sync") {
+		t.Fatalf("expected an interrupted init, got %v", err)
+	}
+	if _, ok := b.Sprite("hello"); !ok {
+		t.Fatalf("expected the interrupted sprite to be kept, got %q", b.Ops())
+	}
+	execs := b.Execs()
+	marker := execs[len(execs)-1]
+	want := []string{initStepCreated, initStepIdentity, initStepGhAuth, initStepAssistantSync}
+	if !strings.Contains(marker.Script(), sevenInitProgressPath) || !slices.Equal(marker.Command[4:], want) {
+		t.Fatalf("expected the cleanup to record progress after the interrupt, got %q", marker.Command)
+	}
+	if last := logs[len(logs)-1]; !strings.Contains(last, "initialization was interrupted after step assistant-sync; kept sprite") {
+		t.Fatalf("expected the log to say what was left behind, got %q", last)
+	}
+}
+
+func TestRunInitInterruptedWithDestroyOnFailure(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := useFakeBackend(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Interrupt the first write of the progress marker.
+	b.OnExec(func(c fake.ExecCall) bool {
+		if strings.Contains(c.Script(), "printf") && strings.Contains(c.Script(), sevenInitProgressPath) {
+			cancel()
+			return true
+		}
+		return false
+	}, fake.Reply{})
+
+	_, err := runInit(ctx, upOptions{AssumeLoggedIn: true, QuietExternal: true, DestroyOnFailure: true})
+	if !errors.Is(err, errInterrupted) {
+		t.Fatalf("expected an interrupted init, got %v", err)
+	}
+	if ops := b.Ops(); ops[len(ops)-1] != "destroy hello" {
+		t.Fatalf("expected the cleanup to destroy the sprite despite the cancelled context, got %q", ops)
+	}
+	if _, ok := b.Sprite("hello"); ok {
+		t.Fatal("expected the sprite to be gone")
+	}
+}
+
+func TestParseInitProgress(t *testing.T) {
+	p := parseInitProgress("created\nidentity\nfuture-step\nidentity\n")
+	if !p.Unfinished || !slices.Equal(p.Done, []string{"created", "identity"}) || p.last() != "identity" {
+		t.Fatalf("unexpected progress: %+v", p)
+	}
+	if p := parseInitProgress(""); p.Unfinished || p.last() != "none" {
+		t.Fatalf("expected no marker to mean a finished init, got %+v", p)
+	}
+}
+
+func TestGstackRetriesOnceWithoutExitFrame(t *testing.T) {
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	b.OnExec(func(c fake.ExecCall) bool { return c.Script() == gstackHealthProbe(gstackDefaultRevision) }, fake.Reply{ExitCode: 1})
+	b.OnExecOnce(func(c fake.ExecCall) bool { return c.LongRunning }, fake.NoExitFrame("gstack setup output completed"))
+
+	opts := upOptions{InstallGstack: true, Logger: func(string) {}}
+	if err := maybeInstallGstack(context.Background(), "hello", "codex", gstackDefaultRevision, opts); err != nil {
+		t.Fatalf("expected the retry to succeed: %v", err)
+	}
+	var installs []fake.ExecCall
+	for _, call := range b.Execs() {
+		if strings.Contains(call.Script(), "./setup --host auto") {
+			installs = append(installs, call)
+		}
+	}
+	if len(installs) != 2 || !installs[0].LongRunning || installs[1].LongRunning || installs[0].Script() != installs[1].Script() {
+		t.Fatalf("expected one long-running attempt and one regular retry of the same script, got %+v", installs)
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"seven/backend"
+)
+
+var checkpointIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+var gitHeadPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+func cmdCheckpoint(args []string) {
+	if len(args) == 0 {
+		checkpointUsage()
+		os.Exit(1)
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "create":
+		cmdCheckpointCreate(args)
+	case "list", "ls":
+		cmdCheckpointList(args)
+	case "restore":
+		cmdCheckpointRestore(args)
+	case "delete", "rm":
+		cmdCheckpointDelete(args)
+	case "help", "-h", "--help":
+		checkpointUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown checkpoint command: %s\n", sub)
+		checkpointUsage()
+		os.Exit(1)
+	}
+}
+
+func checkpointUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  seven checkpoint create [N] [-m message]")
+	fmt.Println("  seven checkpoint list [N]")
+	fmt.Println("  seven checkpoint restore [N] <id> [--yes]")
+	fmt.Println("  seven checkpoint delete [N] <id>")
+	fmt.Println()
+	fmt.Println("N selects sibling #N (1 = main); without it the selected sprite (.sprite) is used.")
+}
+
+func cmdCheckpointCreate(args []string) {
+	fs := flag.NewFlagSet("checkpoint create", flag.ExitOnError)
+	message := fs.String("m", "", "message stored with the checkpoint")
+	ordinal, rest := parseCheckpointArgs(fs, "create", args)
+	if len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "seven checkpoint create failed: unexpected arguments: %s\n", strings.Join(rest, " "))
+		os.Exit(1)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	name := resolveExistingSprite(ctx, "checkpoint create", upOptions{SiblingOrdinal: ordinal})
+
+	comment := checkpointComment(*message, hostGitHead(ctx))
+	fmt.Printf("creating checkpoint of %s: %s\n", name, comment)
+	out, err := activeBackend.CreateCheckpoint(ctx, name, comment)
+	if s := strings.TrimSpace(out); s != "" {
+		fmt.Println(s)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven checkpoint create failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func cmdCheckpointList(args []string) {
+	fs := flag.NewFlagSet("checkpoint list", flag.ExitOnError)
+	ordinal, rest := parseCheckpointArgs(fs, "list", args)
+	if len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "seven checkpoint list failed: unexpected arguments: %s\n", strings.Join(rest, " "))
+		os.Exit(1)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	name := resolveExistingSprite(ctx, "checkpoint list", upOptions{SiblingOrdinal: ordinal})
+
+	entries, err := activeBackend.ListCheckpoints(ctx, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven checkpoint list failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("checkpoints for %s:\n", name)
+	if len(entries) == 0 {
+		fmt.Println("  (none yet — run 'seven checkpoint create -m <message>')")
+		return
+	}
+	for _, entry := range entries {
+		fmt.Printf("  %s\n", entry.Line)
+	}
+}
+
+func cmdCheckpointRestore(args []string) {
+	fs := flag.NewFlagSet("checkpoint restore", flag.ExitOnError)
+	yes := fs.Bool("yes", false, "restore without asking for confirmation")
+	ordinal, rest := parseCheckpointArgs(fs, "restore", args)
+	id := checkpointIDArg("restore", rest)
+	name := resolveExistingSprite(context.Background(), "checkpoint restore", upOptions{SiblingOrdinal: ordinal})
+
+	// Restoring replaces the sprite's whole disk, including uncommitted and
+	// unpushed work inside it, so never do it without an explicit yes.
+	if !*yes {
+		prompt := fmt.Sprintf("restore %s to checkpoint %s? everything written since that checkpoint is lost [y/N] ", name, id)
+		if !confirm(os.Stdin, prompt) {
+			fmt.Println("restore cancelled")
+			os.Exit(1)
+		}
+	}
+	// Ctrl-C stays the prompt's until the answer is in.
+	ctx, stop := interruptContext()
+	defer stop()
+	if err := activeBackend.RestoreCheckpoint(ctx, name, id); err != nil {
+		fmt.Fprintf(os.Stderr, "seven checkpoint restore failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("restored %s to checkpoint %s\n", name, id)
+}
+
+func cmdCheckpointDelete(args []string) {
+	fs := flag.NewFlagSet("checkpoint delete", flag.ExitOnError)
+	ordinal, rest := parseCheckpointArgs(fs, "delete", args)
+	id := checkpointIDArg("delete", rest)
+	ctx, stop := interruptContext()
+	defer stop()
+	name := resolveExistingSprite(ctx, "checkpoint delete", upOptions{SiblingOrdinal: ordinal})
+
+	if out, err := activeBackend.DeleteCheckpoint(ctx, name, id); err != nil {
+		msg := strings.TrimSpace(out)
+		if msg != "" {
+			fmt.Fprintf(os.Stderr, "seven checkpoint delete failed: %v (%s)\n", err, msg)
+		} else {
+			fmt.Fprintf(os.Stderr, "seven checkpoint delete failed: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("deleted checkpoint %s of %s\n", id, name)
+}
+
+// parseCheckpointArgs strips an optional leading sibling number (as in
+// "seven up 2") and parses flags that appear before or after the positional
+// arguments, so "restore 2 v3 --yes" and "restore --yes 2 v3" both work.
+func parseCheckpointArgs(fs *flag.FlagSet, sub string, args []string) (int, []string) {
+	_ = fs.Parse(args)
+	rest := fs.Args()
+	ordinal := 0
+	if len(rest) > 0 {
+		if n, err := strconv.Atoi(rest[0]); err == nil {
+			if n < 1 {
+				fmt.Fprintf(os.Stderr, "seven checkpoint %s failed: sprite number must be a positive integer, got %q\n", sub, rest[0])
+				os.Exit(1)
+			}
+			ordinal = n
+			rest = rest[1:]
+		}
+	}
+	var positional []string
+	for len(rest) > 0 {
+		positional = append(positional, rest[0])
+		_ = fs.Parse(rest[1:])
+		rest = fs.Args()
+	}
+	return ordinal, positional
+}
+
+func checkpointIDArg(sub string, rest []string) string {
+	if len(rest) != 1 {
+		fmt.Fprintf(os.Stderr, "seven checkpoint %s failed: expected exactly one checkpoint id (see 'seven checkpoint list')\n", sub)
+		os.Exit(1)
+	}
+	id := strings.TrimSpace(rest[0])
+	if !checkpointIDPattern.MatchString(id) {
+		fmt.Fprintf(os.Stderr, "seven checkpoint %s failed: invalid checkpoint id %q\n", sub, id)
+		os.Exit(1)
+	}
+	return id
+}
+
+// checkpointComment labels a checkpoint with the user's message and the host
+// checkout's HEAD, so a listing shows which local commit the sprite state
+// corresponds to.
+func checkpointComment(message, head string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if message == "" {
+		message = "seven checkpoint"
+	}
+	if head == "" {
+		return message
+	}
+	return fmt.Sprintf("%s [host %s]", message, head)
+}
+
+// hostGitHead returns the abbreviated HEAD of the host checkout, or "" when the
+// current directory is not a git work tree with at least one commit.
+func hostGitHead(ctx context.Context) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	head, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "rev-parse", "--short=12", "HEAD")
+	if err != nil {
+		return ""
+	}
+	head = strings.TrimSpace(head)
+	if !gitHeadPattern.MatchString(head) {
+		return ""
+	}
+	return head
+}
+
+// confirm prints prompt and reports whether the answer read from in is yes.
+// EOF (e.g. a non-interactive stdin) counts as no.
+func confirm(in io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseCheckpointList extracts checkpoint rows from `sprite checkpoint list`
+// output, skipping blank lines and the header row. Rows are ordered oldest
+// first by the numeric part of their ID when every ID has one.
+func parseCheckpointList(out string) []backend.Checkpoint {
+	var entries []backend.Checkpoint
+	scanner := bufio.NewScanner(strings.NewReader(ansiEscapeRe.ReplaceAllString(out, "")))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.EqualFold(fields[0], "id") || !checkpointIDPattern.MatchString(fields[0]) {
+			continue
+		}
+		created, n := parseCheckpointCreated(fields[1:])
+		entries = append(entries, backend.Checkpoint{
+			ID:      fields[0],
+			Created: created,
+			Comment: strings.Join(fields[1+n:], " "),
+			Line:    line,
+		})
+	}
+	for _, entry := range entries {
+		if _, ok := checkpointOrdinal(entry.ID); !ok {
+			return entries
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		ni, _ := checkpointOrdinal(entries[i].ID)
+		nj, _ := checkpointOrdinal(entries[j].ID)
+		return ni < nj
+	})
+	return entries
+}
+
+// parseCheckpointCreated reads the creation time at the start of a row's
+// remaining fields, as RFC 3339 or "2006-01-02 15:04:05", and reports how many
+// fields it used. A row without one yields the zero time and uses none.
+func parseCheckpointCreated(fields []string) (time.Time, int) {
+	if len(fields) > 0 {
+		if t, err := time.Parse(time.RFC3339, fields[0]); err == nil {
+			return t, 1
+		}
+	}
+	if len(fields) > 1 {
+		if t, err := time.Parse(time.DateTime, fields[0]+" "+fields[1]); err == nil {
+			return t, 2
+		}
+	}
+	return time.Time{}, 0
+}
+
+// checkpointOrdinal returns the number in a checkpoint ID such as "v12".
+func checkpointOrdinal(id string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(id, "v"), "V"))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// autoCheckpointLabel marks checkpoints taken by seven up. Only checkpoints
+// whose comment starts with autoCheckpointPrefix are pruned; ones created with
+// seven checkpoint are never touched, even if their message quotes the label.
+// Matching the prefix alone keeps pruning working when a listing truncates
+// the comment column.
+const (
+	autoCheckpointLabel  = autoCheckpointPrefix + " before seven up"
+	autoCheckpointPrefix = "seven-auto:"
+)
+
+// automaticCheckpoints returns the checkpoints seven up took, oldest first.
+// Rows keep the listing's order when the backend reports no creation times.
+func automaticCheckpoints(entries []backend.Checkpoint) []backend.Checkpoint {
+	var auto []backend.Checkpoint
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Comment, autoCheckpointPrefix) {
+			auto = append(auto, entry)
+		}
+	}
+	sort.SliceStable(auto, func(i, j int) bool {
+		return auto[i].Created.Before(auto[j].Created)
+	})
+	return auto
+}
+
+// autoCheckpoint snapshots an existing sprite before its console opens, so a
+// bad autonomous run can be rolled back to the state at the start of the
+// session, then prunes seven's automatic checkpoints down to the newest keep.
+// The snapshot is the point of the opt-in, so a failure to take it is fatal
+// (a backend without checkpoints only logs a skip); pruning is best-effort.
+func autoCheckpoint(ctx context.Context, spriteName string, opts upOptions) error {
+	keep := opts.CheckpointKeep
+	if keep < 1 {
+		keep = defaultCheckpointKeep
+	}
+	opts.Logger(fmt.Sprintf("[seven up] taking pre-agent checkpoint (keeping last %d)", keep))
+	out, err := activeBackend.CreateCheckpoint(ctx, spriteName, checkpointComment(autoCheckpointLabel, hostGitHead(ctx)))
+	if errors.Is(err, backend.ErrUnsupported) {
+		opts.Logger(fmt.Sprintf("[seven up] the %s backend has no checkpoints; skipping the pre-agent checkpoint", activeBackend.Name()))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("automatic checkpoint failed (pass --checkpoint=false to skip): %w%s", err, gstackOutputTail(out))
+	}
+
+	entries, err := activeBackend.ListCheckpoints(ctx, spriteName)
+	if err != nil {
+		opts.Logger(fmt.Sprintf("[seven up] checkpoint list failed; not pruning old checkpoints: %v", err))
+		return nil
+	}
+	auto := automaticCheckpoints(entries)
+	for len(auto) > keep {
+		if _, err := activeBackend.DeleteCheckpoint(ctx, spriteName, auto[0].ID); err != nil {
+			opts.Logger(fmt.Sprintf("[seven up] pruning checkpoint %s failed: %v", auto[0].ID, err))
+		} else {
+			opts.Logger(fmt.Sprintf("[seven up] pruned old automatic checkpoint %s", auto[0].ID))
+		}
+		auto = auto[1:]
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runSevenCheckpoint runs `seven checkpoint ...` in repo against a fake sprite,
+// feeding stdin, and returns the combined output and error.
+func runSevenCheckpoint(t *testing.T, repo, state, logPath, stdin string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, append([]string{"checkpoint"}, args...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestSevenCheckpointCreateLabelsHostHead(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	head, err := exec.Command("git", "-C", repo, "rev-parse", "--short=12", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("resolve test repo HEAD: %v", err)
+	}
+
+	out, err := runSevenCheckpoint(t, repo, state, logPath, "", "create", "-m", "before refactor")
+	if err != nil {
+		t.Fatalf("seven checkpoint create failed: %v\n%s", err, out)
+	}
+	logData, _ := os.ReadFile(logPath)
+	want := "checkpoint create -s hello --comment before refactor [host " + strings.TrimSpace(string(head)) + "]"
+	if !strings.Contains(string(logData), want) {
+		t.Fatalf("expected %q in log, got: %s", want, logData)
+	}
+
+	out, err = runSevenCheckpoint(t, repo, state, logPath, "", "list")
+	if err != nil {
+		t.Fatalf("seven checkpoint list failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "checkpoints for hello") || !strings.Contains(out, "v1") || !strings.Contains(out, "before refactor") {
+		t.Fatalf("expected created checkpoint in listing, got: %s", out)
+	}
+}
+
+func TestSevenCheckpointRestoreSiblingRequiresConfirmation(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("seven\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("seven\nseven-02\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	out, err := runSevenCheckpoint(t, repo, state, logPath, "n\n", "restore", "2", "v3")
+	if err == nil || !strings.Contains(out, "restore cancelled") {
+		t.Fatalf("expected declined restore to fail, err=%v output=%s", err, out)
+	}
+	logData, _ := os.ReadFile(logPath)
+	if strings.Contains(string(logData), "restore -s") {
+		t.Fatalf("restore must not run without confirmation, got: %s", logData)
+	}
+
+	out, err = runSevenCheckpoint(t, repo, state, logPath, "y\n", "restore", "2", "v3")
+	if err != nil {
+		t.Fatalf("seven checkpoint restore failed: %v\n%s", err, out)
+	}
+	logData, _ = os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "restore -s seven-02 v3") {
+		t.Fatalf("expected restore of sibling seven-02, got: %s", logData)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, ".sprite")); strings.TrimSpace(string(data)) != "seven" {
+		t.Fatalf("checkpoint restore must not change the selection, got %q", data)
+	}
+}
+
+func TestSevenCheckpointRestoreYesSkipsPrompt(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("seven\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("seven\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	out, err := runSevenCheckpoint(t, repo, state, logPath, "", "restore", "v1", "--yes")
+	if err != nil {
+		t.Fatalf("seven checkpoint restore --yes failed: %v\n%s", err, out)
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "restore -s seven v1") {
+		t.Fatalf("expected restore of selected sprite, got: %s", logData)
+	}
+}
+
+func TestSevenCheckpointRejectsMissingSprite(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("seven\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+
+	out, err := runSevenCheckpoint(t, repo, state, logPath, "", "create", "3")
+	if err == nil || !strings.Contains(out, "sprite not found: seven-03") {
+		t.Fatalf("expected missing sibling to fail, err=%v output=%s", err, out)
+	}
+}
+
+func TestCheckpointComment(t *testing.T) {
+	for _, tc := range []struct {
+		message, head, want string
+	}{
+		{"before refactor", "0123456789ab", "before refactor [host 0123456789ab]"},
+		{"  multi\nline  msg ", "", "multi line msg"},
+		{"", "0123456789ab", "seven checkpoint [host 0123456789ab]"},
+	} {
+		if got := checkpointComment(tc.message, tc.head); got != tc.want {
+			t.Fatalf("checkpointComment(%q, %q) = %q, want %q", tc.message, tc.head, got, tc.want)
+		}
+	}
+}
+
+func TestParseCheckpointList(t *testing.T) {
+	out := "ID   CREATED               COMMENT\n\x1b[1mv10\x1b[0m 2026-01-03T00:00:00Z later\nv2   2026-01-02T00:00:00Z earlier\n\nv9   2026-01-02T12:00:00Z middle\n"
+	entries := parseCheckpointList(out)
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if got := strings.Join(ids, ","); got != "v2,v9,v10" {
+		t.Fatalf("expected checkpoints ordered oldest first, got %q", got)
+	}
+	if entries[2].Comment != "later" || entries[2].Created.Day() != 3 {
+		t.Fatalf("expected the comment and creation time parsed, got %+v", entries[2])
+	}
+	if !strings.Contains(entries[2].Line, "later") {
+		t.Fatalf("expected row text to be preserved, got %q", entries[2].Line)
+	}
+}
+
+func TestAutomaticCheckpoints(t *testing.T) {
+	out := "ID  CREATED               COMMENT\n" +
+		"v7  2026-01-03T00:00:00Z " + autoCheckpointLabel + " [host 0123456789ab]\n" +
+		"v8  2026-01-03T01:00:00Z before reverting " + autoCheckpointLabel + "\n" +
+		"v9  2026-01-01T00:00:00Z seven-auto: before se…\n" +
+		"v10 2026-01-02T00:00:00Z " + autoCheckpointLabel + "\n"
+	var ids []string
+	for _, entry := range automaticCheckpoints(parseCheckpointList(out)) {
+		ids = append(ids, entry.ID)
+	}
+	if got := strings.Join(ids, ","); got != "v9,v10,v7" {
+		t.Fatalf("expected automatic checkpoints by creation time without the quoting manual one, got %q", got)
+	}
+}
+
+func TestSevenUpAutoCheckpointPrunesOnlyAutomaticCheckpoints(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	existing := "v1 2026-01-01T00:00:00Z " + autoCheckpointLabel + "\n" +
+		"v2 2026-01-01T00:00:00Z before refactor\n" +
+		"v3 2026-01-01T00:00:00Z " + autoCheckpointLabel + "\n"
+	if err := os.WriteFile(state+".checkpoints", []byte(existing), 0o644); err != nil {
+		t.Fatalf("failed to write checkpoints: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte("[checkpoint]\nauto = true\nkeep = 2\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+
+	log := runSevenUpForLog(t, repo, state, logPath, nil)
+	if !strings.Contains(log, "checkpoint create -s hello --comment "+autoCheckpointLabel) {
+		t.Fatalf("expected automatic checkpoint, got: %s", log)
+	}
+	if !strings.Contains(log, "checkpoint delete -s hello v1") {
+		t.Fatalf("expected oldest automatic checkpoint pruned, got: %s", log)
+	}
+	if strings.Contains(log, "checkpoint delete -s hello v2") || strings.Contains(log, "checkpoint delete -s hello v3") {
+		t.Fatalf("only automatic checkpoints beyond keep may be pruned, got: %s", log)
+	}
+	if strings.Index(log, "checkpoint create") > strings.Index(log, "console -s hello") {
+		t.Fatalf("checkpoint must be taken before the console opens, got: %s", log)
+	}
+}
+
+func TestSevenUpCheckpointFlagOverridesProjectConfig(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte("[checkpoint]\nauto = true\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+
+	log := runSevenUpForLog(t, repo, state, logPath, nil, "--checkpoint=false")
+	if strings.Contains(log, "checkpoint create") {
+		t.Fatalf("--checkpoint=false must override .seven.toml, got: %s", log)
+	}
+}
+
+func TestSevenUpAutoCheckpointFailureBlocksConsole(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	cmd := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--checkpoint")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"HOME="+t.TempDir(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_FAIL_CHECKPOINT=1",
+	)
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "automatic checkpoint failed") {
+		t.Fatalf("expected checkpoint failure to fail seven up, err=%v output=%s", err, out)
+	}
+	logData, _ := os.ReadFile(logPath)
+	if strings.Contains(string(logData), "console -s") {
+		t.Fatalf("console must not open without the requested checkpoint: %s", logData)
+	}
+}
+
+func TestSevenUpSkipsAutoCheckpointForNewSprite(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	log := runSevenUpForLog(t, repo, state, logPath, nil, "--checkpoint")
+	if strings.Contains(log, "checkpoint create") {
+		t.Fatalf("a freshly created sprite has nothing to roll back to, got: %s", log)
+	}
+}
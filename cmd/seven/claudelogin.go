@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ensureSpriteClaudeLogin gives the sprite a Claude Code login of its own, so
+// that it never shares a refresh token with the host: whichever side refreshes
+// first spends the token the other holds ("token has already been used"). It
+// runs `claude auth login` in the sprite on this terminal; the device-code
+// flow prints a URL to open on the host and waits for the code it shows.
+// Unless force is set, a sprite that is already logged in is left alone.
+// phase prefixes log lines ("[seven up]" or "[seven init]").
+func ensureSpriteClaudeLogin(ctx context.Context, spriteName, phase string, force bool, opts upOptions) error {
+	if err := spriteExec(ctx, spriteName, nil, true, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
+		return errors.New("cannot log claude in: claude is not installed in the sprite")
+	}
+	if !force {
+		status, err := spriteClaudeAuthStatus(ctx, spriteName)
+		switch {
+		case err != nil:
+			opts.Logger(fmt.Sprintf("%s claude auth validation failed: %v", phase, err))
+		case status.LoggedIn:
+			opts.Logger(phase + " claude is already logged in in the sprite")
+			return nil
+		case status.TokenReused:
+			opts.Logger(phase + " claude's refresh token in the sprite was already used by another copy; logging in again")
+		}
+	}
+
+	opts.Logger(phase + " logging claude in inside the sprite: open the URL it prints on this machine and enter the code")
+	if err := spriteExec(ctx, spriteName, nil, false, "claude", "auth", "login"); err != nil {
+		return fmt.Errorf("claude auth login in sprite: %w", err)
+	}
+	status, err := spriteClaudeAuthStatus(ctx, spriteName)
+	if err != nil {
+		return fmt.Errorf("check claude login in sprite: %w", err)
+	}
+	if !status.LoggedIn {
+		return errors.New("claude is still logged out in the sprite after login")
+	}
+	opts.Logger(phase + " claude is logged in in the sprite with credentials of its own")
+	return nil
+}
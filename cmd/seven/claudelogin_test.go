@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"seven/backend/fake"
+)
+
+func TestParseClaudeAuthStatus(t *testing.T) {
+	cases := map[string]struct {
+		out  string
+		want claudeAuthStatus
+		ok   bool
+	}{
+		"logged in":        {out: `{"loggedIn":true}`, want: claudeAuthStatus{LoggedIn: true}, ok: true},
+		"logged out":       {out: `{"loggedIn":false}`, ok: true},
+		"reused in json":   {out: `{"loggedIn":false,"error":"Refresh token has already been used"}`, want: claudeAuthStatus{TokenReused: true}, ok: true},
+		"reused as stderr": {out: "OAuth error: invalid_grant (token has already been used)\n{\"loggedIn\":true}", want: claudeAuthStatus{TokenReused: true}, ok: true},
+		"not json":         {out: "command not found"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, ok := parseClaudeAuthStatus(tc.out)
+			if got != tc.want || ok != tc.ok {
+				t.Fatalf("got %+v, %v; want %+v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestEnsureSpriteClaudeLogin(t *testing.T) {
+	cases := map[string]struct {
+		force     bool
+		before    string
+		wantLogin bool
+	}{
+		"logged out":         {before: `{"loggedIn":false}`, wantLogin: true},
+		"token reused":       {before: `{"error":"token has already been used"}`, wantLogin: true},
+		"already logged in":  {before: `{"loggedIn":true}`},
+		"forced when logged": {force: true, before: `{"loggedIn":true}`, wantLogin: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := useFakeBackend(t)
+			b.AddSprite("hello")
+			if !tc.force {
+				b.OnExecOnce(fake.CommandContains("claude auth status"), fake.Reply{Stdout: tc.before})
+			}
+			b.OnExec(fake.CommandContains("claude auth status"), fake.Reply{Stdout: `{"loggedIn":true}`})
+
+			var logs []string
+			opts := upOptions{Logger: func(msg string) { logs = append(logs, msg) }}
+			if err := ensureSpriteClaudeLogin(context.Background(), "hello", "[seven init]", tc.force, opts); err != nil {
+				t.Fatalf("ensureSpriteClaudeLogin failed: %v\n%s", err, strings.Join(logs, "\n"))
+			}
+			if len(logs) == 0 || !strings.HasPrefix(logs[0], "[seven init] ") {
+				t.Fatalf("expected log lines prefixed with the caller's phase, got %q", logs)
+			}
+			_, loggedIn := b.FindExec("claude auth login")
+			if loggedIn != tc.wantLogin {
+				t.Fatalf("expected login run = %v, got %v\n%s", tc.wantLogin, loggedIn, strings.Join(logs, "\n"))
+			}
+		})
+	}
+}
+
+func TestEnsureSpriteClaudeLoginFailsWhenStillLoggedOut(t *testing.T) {
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	b.OnExec(fake.CommandContains("claude auth status"), fake.Reply{Stdout: `{"loggedIn":false}`})
+
+	err := ensureSpriteClaudeLogin(context.Background(), "hello", "[seven up]", false, upOptions{Logger: func(string) {}})
+	if err == nil || !strings.Contains(err.Error(), "still logged out") {
+		t.Fatalf("expected a still-logged-out error, got %v", err)
+	}
+}
+
+func TestClaudeOwnLoginKeepsHostCredentialsOut(t *testing.T) {
+	state := hostAssistantState{
+		ClaudeConfigPath:  "/host/.claude/settings.json",
+		ClaudeAuthPath:    "/host/.claude.json",
+		ClaudeCredentials: claudeCredentialsSource{FilePath: "/host/.claude/.credentials.json"},
+	}
+	var logs []string
+	state = skipExcludedCredentialSync(state, "[seven init]", upOptions{
+		ClaudeOwnLogin: true,
+		Logger:         func(msg string) { logs = append(logs, msg) },
+	})
+	if state.ClaudeAuthPath != "" || state.ClaudeCredentials.present() || state.ClaudeConfigPath == "" {
+		t.Fatalf("expected only the claude config to stay, got %+v", state)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "the sprite logs in on its own") {
+		t.Fatalf("unexpected logs: %q", logs)
+	}
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// projectConfigFileName is the committed, per-repo configuration file read from
+// the host checkout. It is optional; a repo without one gets seven's defaults.
+const projectConfigFileName = ".seven.toml"
+
+const defaultCheckpointKeep = 5
+
+var configKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+var configSectionPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// configValue is one parsed right-hand side. Seven's config files use a small,
+// strict TOML subset: strings, integers, booleans, and single-line arrays of
+// strings or integers. Anything else is rejected with its line number.
+type configValue struct {
+	line    int
+	kind    string // "string", "int", "bool", or "array"
+	str     string
+	num     int
+	boolean bool
+	list    []configValue
+}
+
+type configEntry struct {
+	section string // "" for top-level keys
+	key     string
+	value   configValue
+}
+
+// configDocument keeps entries in file order so validation always reports the
+// first offending line.
+type configDocument struct {
+	entries  []configEntry
+	sections []configEntry // one per [section] header; value.line is the header line
+}
+
+type projectConfig struct {
+	Sprite     spriteConfig
+	Up         upDefaults
+	Checkpoint checkpointPolicy
+	Dev        devConfig
+	Ide        ideConfig
+	MCP        mcpConfig
+}
+
+// spriteConfig names the sprite family for everyone working on the repo, so
+// the main sprite and its siblings (<name>-02, ...) do not depend on what each
+// teammate called their checkout directory. A local .sprite still wins.
+type spriteConfig struct {
+	Name string
+}
+
+// upDefaults are the team's defaults for seven up / seven init flags.
+type upDefaults struct {
+	Assistant string
+	Gstack    bool
+	FromHost  bool
+}
+
+// override applies the --assistant, --gstack, and --from-host flags that were
+// given explicitly on the command line on top of the .seven.toml defaults.
+func (d *upDefaults) override(fs *flag.FlagSet, assistant string, gstack, fromHost bool) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "assistant":
+			d.Assistant = assistant
+		case "gstack":
+			d.Gstack = gstack
+		case "from-host":
+			d.FromHost = fromHost
+		}
+	})
+}
+
+// checkpointPolicy controls the automatic checkpoint seven up takes of an
+// existing sprite before its console opens.
+type checkpointPolicy struct {
+	Auto bool
+	Keep int
+}
+
+// devConfig holds the ports seven dev forwards when none are given on the
+// command line, so the whole team shares one mapping.
+type devConfig struct {
+	Ports []devPort
+}
+
+// ideConfig pins the openvscode-server release seven ide installs and the
+// local port it is forwarded to.
+type ideConfig struct {
+	Pin  idePin
+	Port int
+}
+
+// mcpConfig names the MCP servers the repo's agents depend on. Init fails
+// unless each is configured on the host and live in the sprite after sync.
+type mcpConfig struct {
+	Required []string
+}
+
+// loadProjectConfig reads .seven.toml from the current directory. A missing
+// file yields the defaults; a present file must validate completely.
+func loadProjectConfig() (projectConfig, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return projectConfig{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(cwd, projectConfigFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return defaultProjectConfig(), nil
+	}
+	if err != nil {
+		return projectConfig{}, fmt.Errorf("read %s: %w", projectConfigFileName, err)
+	}
+	return parseProjectConfig(string(data))
+}
+
+func defaultProjectConfig() projectConfig {
+	return projectConfig{
+		Checkpoint: checkpointPolicy{Keep: defaultCheckpointKeep},
+		Ide:        ideConfig{Port: ideDefaultPort},
+	}
+}
+
+func parseProjectConfig(contents string) (projectConfig, error) {
+	config := defaultProjectConfig()
+	doc, err := parseConfigDocument(projectConfigFileName, contents)
+	if err != nil {
+		return projectConfig{}, err
+	}
+	if err := doc.requireSections(projectConfigFileName, "sprite", "up", "checkpoint", "dev", "ide", "mcp"); err != nil {
+		return projectConfig{}, err
+	}
+	for _, entry := range doc.entries {
+		value := entry.value
+		switch entry.section + "." + entry.key {
+		case "sprite.name":
+			config.Sprite.Name, err = value.asFamilyName(projectConfigFileName, entry.key)
+		case "up.assistant":
+			config.Up.Assistant, err = value.asAssistant(projectConfigFileName, entry.key)
+		case "up.gstack":
+			config.Up.Gstack, err = value.asBool(projectConfigFileName, entry.key)
+		case "up.from_host":
+			config.Up.FromHost, err = value.asBool(projectConfigFileName, entry.key)
+		case "checkpoint.auto":
+			config.Checkpoint.Auto, err = value.asBool(projectConfigFileName, entry.key)
+		case "checkpoint.keep":
+			config.Checkpoint.Keep, err = value.asPositiveInt(projectConfigFileName, entry.key)
+		case "dev.ports":
+			config.Dev.Ports, err = value.asDevPorts(projectConfigFileName, entry.key)
+		case "ide.version":
+			config.Ide.Pin.Version, err = value.asString(projectConfigFileName, entry.key)
+		case "ide.sha256_x86_64":
+			config.Ide.Pin.SHA256X86_64, err = value.asString(projectConfigFileName, entry.key)
+		case "ide.sha256_arm64":
+			config.Ide.Pin.SHA256Arm64, err = value.asString(projectConfigFileName, entry.key)
+		case "ide.port":
+			config.Ide.Port, err = value.asPort(projectConfigFileName, entry.key)
+		case "mcp.required":
+			config.MCP.Required, err = value.asMCPServerNames(projectConfigFileName, entry.key)
+		default:
+			err = doc.unknownKey(projectConfigFileName, entry)
+		}
+		if err != nil {
+			return projectConfig{}, err
+		}
+	}
+	if config.Ide.Pin.configured() {
+		if err := config.Ide.Pin.validate(); err != nil {
+			return projectConfig{}, fmt.Errorf("invalid %s: %v", projectConfigFileName, err)
+		}
+	}
+	return config, nil
+}
+
+// parseConfigDocument parses the TOML subset shared by seven's config files.
+// Parsing is strict: duplicate keys or sections, unterminated strings, nested
+// tables, and trailing garbage are all errors naming the offending line.
+func parseConfigDocument(file, contents string) (configDocument, error) {
+	doc := configDocument{}
+	seenSections := map[string]bool{}
+	seenKeys := map[string]bool{}
+	section := ""
+	for index, raw := range strings.Split(contents, "\n") {
+		lineNo := index + 1
+		line := strings.TrimSpace(stripConfigComment(raw))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return configDocument{}, fmt.Errorf("invalid %s line %d: malformed section header", file, lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if !configSectionPattern.MatchString(name) {
+				return configDocument{}, fmt.Errorf("invalid %s line %d: invalid section name %q", file, lineNo, name)
+			}
+			if seenSections[name] {
+				return configDocument{}, fmt.Errorf("invalid %s line %d: duplicate section [%s]", file, lineNo, name)
+			}
+			seenSections[name] = true
+			doc.sections = append(doc.sections, configEntry{section: name, value: configValue{line: lineNo}})
+			section = name
+			continue
+		}
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return configDocument{}, fmt.Errorf("invalid %s line %d: expected key = value", file, lineNo)
+		}
+		key := strings.TrimSpace(line[:eq])
+		if !configKeyPattern.MatchString(key) {
+			return configDocument{}, fmt.Errorf("invalid %s line %d: invalid key %q", file, lineNo, key)
+		}
+		value, rest, err := parseConfigValue(strings.TrimSpace(line[eq+1:]), lineNo)
+		if err != nil {
+			return configDocument{}, fmt.Errorf("invalid %s line %d: %v", file, lineNo, err)
+		}
+		if strings.TrimSpace(rest) != "" {
+			return configDocument{}, fmt.Errorf("invalid %s line %d: unexpected text after value", file, lineNo)
+		}
+		if seenKeys[section+"\x00"+key] {
+			return configDocument{}, fmt.Errorf("invalid %s line %d: duplicate key %q", file, lineNo, key)
+		}
+		seenKeys[section+"\x00"+key] = true
+		doc.entries = append(doc.entries, configEntry{section: section, key: key, value: value})
+	}
+	return doc, nil
+}
+
+// stripConfigComment removes a trailing # comment that is not inside a string.
+func stripConfigComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseConfigValue(text string, line int) (configValue, string, error) {
+	switch {
+	case text == "":
+		return configValue{}, "", errors.New("missing value")
+	case text[0] == '"':
+		var b strings.Builder
+		for i := 1; i < len(text); i++ {
+			c := text[i]
+			if c == '"' {
+				return configValue{line: line, kind: "string", str: b.String()}, text[i+1:], nil
+			}
+			if c != '\\' {
+				b.WriteByte(c)
+				continue
+			}
+			i++
+			if i >= len(text) {
+				break
+			}
+			switch text[i] {
+			case '"', '\\':
+				b.WriteByte(text[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return configValue{}, "", fmt.Errorf("unsupported escape \\%c", text[i])
+			}
+		}
+		return configValue{}, "", errors.New("unterminated string")
+	case text[0] == '\'':
+		end := strings.IndexByte(text[1:], '\'')
+		if end < 0 {
+			return configValue{}, "", errors.New("unterminated string")
+		}
+		return configValue{line: line, kind: "string", str: text[1 : end+1]}, text[end+2:], nil
+	case text[0] == '[':
+		value := configValue{line: line, kind: "array"}
+		rest := strings.TrimSpace(text[1:])
+		for {
+			if strings.HasPrefix(rest, "]") {
+				return value, rest[1:], nil
+			}
+			item, after, err := parseConfigValue(rest, line)
+			if err != nil {
+				return configValue{}, "", err
+			}
+			if item.kind == "array" || item.kind == "bool" {
+				return configValue{}, "", errors.New("arrays may contain only strings or integers")
+			}
+			if len(value.list) > 0 && value.list[0].kind != item.kind {
+				return configValue{}, "", errors.New("arrays must not mix types")
+			}
+			value.list = append(value.list, item)
+			rest = strings.TrimSpace(after)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return configValue{}, "", errors.New("unterminated array")
+			}
+		}
+	}
+	end := strings.IndexAny(text, " \t,]")
+	if end < 0 {
+		end = len(text)
+	}
+	token, rest := text[:end], text[end:]
+	switch token {
+	case "true", "false":
+		return configValue{line: line, kind: "bool", boolean: token == "true"}, rest, nil
+	}
+	n, err := strconv.Atoi(strings.ReplaceAll(token, "_", ""))
+	if err != nil {
+		return configValue{}, "", fmt.Errorf("unsupported value %q (quote strings)", token)
+	}
+	return configValue{line: line, kind: "int", num: n}, rest, nil
+}
+
+func (v configValue) asBool(file, key string) (bool, error) {
+	if v.kind != "bool" {
+		return false, fmt.Errorf("invalid %s line %d: %s must be true or false", file, v.line, key)
+	}
+	return v.boolean, nil
+}
+
+func (v configValue) asString(file, key string) (string, error) {
+	if v.kind != "string" {
+		return "", fmt.Errorf("invalid %s line %d: %s must be a quoted string", file, v.line, key)
+	}
+	return v.str, nil
+}
+
+func (v configValue) asAssistant(file, key string) (string, error) {
+	if v.kind != "string" {
+		return "", fmt.Errorf("invalid %s line %d: %s must be a quoted string", file, v.line, key)
+	}
+	assistant, err := normalizeAssistant(v.str)
+	if err != nil || assistant == "" {
+		return "", fmt.Errorf("invalid %s line %d: %s must be \"codex\", \"claude\", \"gemini\", or \"cursor\"", file, v.line, key)
+	}
+	return assistant, nil
+}
+
+// asFamilyName accepts a sprite name usable as a family base: a name that
+// already ends in a sibling suffix like -02 would make ordinals ambiguous.
+func (v configValue) asFamilyName(file, key string) (string, error) {
+	if v.kind != "string" {
+		return "", fmt.Errorf("invalid %s line %d: %s must be a quoted string", file, v.line, key)
+	}
+	if err := validateSpriteName(v.str); err != nil {
+		return "", fmt.Errorf("invalid %s line %d: %v", file, v.line, err)
+	}
+	if spriteSuffixRe.MatchString(v.str) {
+		return "", fmt.Errorf("invalid %s line %d: %s %q must not end in a sibling number like -02", file, v.line, key, v.str)
+	}
+	return v.str, nil
+}
+
+func (v configValue) asPort(file, key string) (int, error) {
+	if v.kind != "int" || v.num < 1 || v.num > 65535 {
+		return 0, fmt.Errorf("invalid %s line %d: %s must be a port between 1 and 65535", file, v.line, key)
+	}
+	return v.num, nil
+}
+
+func (v configValue) asPositiveInt(file, key string) (int, error) {
+	if v.kind != "int" || v.num < 1 {
+		return 0, fmt.Errorf("invalid %s line %d: %s must be a positive integer", file, v.line, key)
+	}
+	return v.num, nil
+}
+
+// asDevPorts accepts an array of port numbers (3000) or "LOCAL:REMOTE" strings.
+func (v configValue) asDevPorts(file, key string) ([]devPort, error) {
+	if v.kind != "array" {
+		return nil, fmt.Errorf("invalid %s line %d: %s must be an array of ports", file, v.line, key)
+	}
+	ports := make([]devPort, 0, len(v.list))
+	for _, item := range v.list {
+		text := item.str
+		if item.kind == "int" {
+			text = strconv.Itoa(item.num)
+		}
+		port, err := parseDevPort(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s line %d: %v", file, v.line, err)
+		}
+		ports = append(ports, port)
+	}
+	if err := uniqueDevPorts(ports); err != nil {
+		return nil, fmt.Errorf("invalid %s line %d: %v", file, v.line, err)
+	}
+	return ports, nil
+}
+
+// asMCPServerNames accepts an array of distinct MCP server names.
+func (v configValue) asMCPServerNames(file, key string) ([]string, error) {
+	if v.kind != "array" {
+		return nil, fmt.Errorf("invalid %s line %d: %s must be an array of MCP server names", file, v.line, key)
+	}
+	var names []string
+	for _, item := range v.list {
+		if item.kind != "string" || !configKeyPattern.MatchString(item.str) {
+			return nil, fmt.Errorf("invalid %s line %d: %s entries must be MCP server names (letters, digits, _ and -)", file, v.line, key)
+		}
+		if slices.Contains(names, item.str) {
+			return nil, fmt.Errorf("invalid %s line %d: duplicate %s entry %q", file, v.line, key, item.str)
+		}
+		names = append(names, item.str)
+	}
+	return names, nil
+}
+
+// requireSections rejects any [section] header not in known, so a typo such as
+// [checkpionts] fails instead of being silently ignored.
+func (doc configDocument) requireSections(file string, known ...string) error {
+	for _, header := range doc.sections {
+		ok := false
+		for _, name := range known {
+			if header.section == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("invalid %s line %d: unknown section [%s]", file, header.value.line, header.section)
+		}
+	}
+	return nil
+}
+
+func (doc configDocument) unknownKey(file string, entry configEntry) error {
+	if entry.section == "" {
+		return fmt.Errorf("invalid %s line %d: unknown key %q", file, entry.value.line, entry.key)
+	}
+	return fmt.Errorf("invalid %s line %d: unknown key %q in [%s]", file, entry.value.line, entry.key, entry.section)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseProjectConfig(t *testing.T) {
+	config, err := parseProjectConfig("# team defaults\n[checkpoint]\nauto = true # snapshot every session\nkeep = 3\n")
+	if err != nil {
+		t.Fatalf("expected valid config: %v", err)
+	}
+	if !config.Checkpoint.Auto || config.Checkpoint.Keep != 3 {
+		t.Fatalf("unexpected checkpoint policy: %+v", config.Checkpoint)
+	}
+
+	config, err = parseProjectConfig("[mcp]\nrequired = [\"github\", \"linear\"]\n")
+	if err != nil || !slices.Equal(config.MCP.Required, []string{"github", "linear"}) {
+		t.Fatalf("expected required MCP servers, got %+v err=%v", config.MCP, err)
+	}
+
+	config, err = parseProjectConfig("")
+	if err != nil || config.Checkpoint.Auto || config.Checkpoint.Keep != defaultCheckpointKeep {
+		t.Fatalf("expected defaults for empty config, got %+v err=%v", config, err)
+	}
+}
+
+func TestParseProjectConfigRejectsInvalidLines(t *testing.T) {
+	for name, tc := range map[string]struct {
+		contents string
+		want     string
+	}{
+		"unknown section":   {"[checkpoint]\nauto = true\n\n[checkpionts]\n", "line 4: unknown section [checkpionts]"},
+		"unknown key":       {"[checkpoint]\nenabled = true\n", "line 2: unknown key \"enabled\" in [checkpoint]"},
+		"top-level key":     {"auto = true\n", "line 1: unknown key \"auto\""},
+		"wrong type":        {"[checkpoint]\nauto = \"yes\"\n", "line 2: auto must be true or false"},
+		"non-positive keep": {"[checkpoint]\nkeep = 0\n", "line 2: keep must be a positive integer"},
+		"duplicate key":     {"[checkpoint]\nkeep = 1\nkeep = 2\n", "line 3: duplicate key \"keep\""},
+		"duplicate section": {"[checkpoint]\n[checkpoint]\n", "line 2: duplicate section [checkpoint]"},
+		"bare word":         {"[checkpoint]\nauto = yes\n", "line 2: unsupported value"},
+		"missing equals":    {"[checkpoint]\nauto\n", "line 2: expected key = value"},
+		"unterminated":      {"[checkpoint]\nauto = \"true\n", "line 2: unterminated string"},
+		"trailing text":     {"[checkpoint]\nkeep = 2 3\n", "line 2: unexpected text after value"},
+		"array of tables":   {"[[checkpoint]]\n", "line 1: malformed section header"},
+		"mcp not array":     {"[mcp]\nrequired = \"github\"\n", "line 2: required must be an array of MCP server names"},
+		"mcp duplicate":     {"[mcp]\nrequired = [\"github\", \"github\"]\n", "line 2: duplicate required entry \"github\""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseProjectConfig(tc.contents)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestParseConfigDocumentValues(t *testing.T) {
+	doc, err := parseConfigDocument("test.toml", "name = \"a # not a comment\"\nlit = 'x\\y'\nports = [3000, 8_080,]\nnames = [\"a\", 'b']\n")
+	if err != nil {
+		t.Fatalf("expected valid document: %v", err)
+	}
+	got := map[string]configValue{}
+	for _, entry := range doc.entries {
+		got[entry.key] = entry.value
+	}
+	if got["name"].str != "a # not a comment" {
+		t.Fatalf("expected # inside string preserved, got %q", got["name"].str)
+	}
+	if got["lit"].str != `x\y` {
+		t.Fatalf("expected literal string without escapes, got %q", got["lit"].str)
+	}
+	if ports := got["ports"].list; len(ports) != 2 || ports[0].num != 3000 || ports[1].num != 8080 {
+		t.Fatalf("unexpected integer array: %+v", ports)
+	}
+	if names := got["names"].list; len(names) != 2 || names[1].str != "b" {
+		t.Fatalf("unexpected string array: %+v", names)
+	}
+	if _, err := parseConfigDocument("test.toml", "mixed = [1, \"a\"]\n"); err == nil {
+		t.Fatal("expected mixed-type array to fail")
+	}
+}
+
+func TestParseProjectConfigDevPorts(t *testing.T) {
+	config, err := parseProjectConfig("[dev]\nports = [3000, 5173]\n")
+	if err != nil {
+		t.Fatalf("expected valid dev ports: %v", err)
+	}
+	if len(config.Dev.Ports) != 2 || config.Dev.Ports[1].spec() != "5173" {
+		t.Fatalf("unexpected dev ports: %+v", config.Dev.Ports)
+	}
+	config, err = parseProjectConfig("[dev]\nports = [\"8080:3000\"]\n")
+	if err != nil || config.Dev.Ports[0].spec() != "8080:3000" {
+		t.Fatalf("expected remapped dev port, got %+v err=%v", config.Dev.Ports, err)
+	}
+	for _, contents := range []string{
+		"[dev]\nports = 3000\n",
+		"[dev]\nports = [0]\n",
+		"[dev]\nports = [3000, 3000]\n",
+	} {
+		if _, err := parseProjectConfig(contents); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Fatalf("expected line-numbered error for %q, got %v", contents, err)
+		}
+	}
+}
+
+func TestParseProjectConfigUpDefaults(t *testing.T) {
+	config, err := parseProjectConfig("[sprite]\nname = \"team-app\"\n\n[up]\nassistant = \"Claude\"\ngstack = true\nfrom_host = true\n")
+	if err != nil {
+		t.Fatalf("expected valid config: %v", err)
+	}
+	if config.Sprite.Name != "team-app" {
+		t.Fatalf("unexpected sprite name: %q", config.Sprite.Name)
+	}
+	if config.Up != (upDefaults{Assistant: "claude", Gstack: true, FromHost: true}) {
+		t.Fatalf("unexpected up defaults: %+v", config.Up)
+	}
+
+	for name, tc := range map[string]struct {
+		contents string
+		want     string
+	}{
+		"unknown assistant": {"[up]\n\nassistant = \"copilot\"\n", "line 3: assistant must be \"codex\", \"claude\", \"gemini\", or \"cursor\""},
+		"empty assistant":   {"[up]\nassistant = \"\"\n", "line 2: assistant must be"},
+		"invalid name":      {"[sprite]\nname = \"Team_App\"\n", "line 2: sprite name \"Team_App\" is invalid"},
+		"sibling name":      {"[sprite]\nname = \"team-02\"\n", "line 2: name \"team-02\" must not end in a sibling number"},
+		"gstack string":     {"[up]\ngstack = \"yes\"\n", "line 2: gstack must be true or false"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseProjectConfig(tc.contents)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestSevenUpUsesProjectConfigDefaults(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	config := "[sprite]\nname = \"team-app\"\n\n[up]\ngstack = true\n"
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+
+	log := runSevenUpForLog(t, repo, state, logPath, nil, "--no-console")
+	if !strings.Contains(log, "create team-app\n") {
+		t.Fatalf("expected the configured family name, got: %s", log)
+	}
+	if !strings.Contains(log, "garrytan/gstack") {
+		t.Fatalf("expected [up] gstack = true to install gstack, got: %s", log)
+	}
+
+	if err := os.Remove(filepath.Join(repo, ".sprite")); err != nil {
+		t.Fatalf("failed to remove .sprite: %v", err)
+	}
+	if err := os.Truncate(logPath, 0); err != nil {
+		t.Fatal(err)
+	}
+	log = runSevenUpForLog(t, repo, state, logPath, nil, "--no-console", "--new", "--gstack=false")
+	if !strings.Contains(log, "create team-app-02") {
+		t.Fatalf("expected siblings named after the configured family, got: %s", log)
+	}
+	if strings.Contains(log, "garrytan/gstack") {
+		t.Fatalf("--gstack=false must override .seven.toml, got: %s", log)
+	}
+}
+
+func TestSevenInitRejectsInvalidProjectConfig(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte("[up]\nassistant = \"copilot\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+	cmd := exec.Command(testSevenBin, "init", "--assume-logged-in")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "invalid .seven.toml line 2") {
+		t.Fatalf("expected a line-numbered config error, err=%v output=%s", err, out)
+	}
+	if logData, _ := os.ReadFile(logPath); strings.Contains(string(logData), "create") {
+		t.Fatalf("an invalid config must stop init before any sprite is created, got: %s", logData)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/exec"
+	"strings"
+
+	"seven/backend"
+)
+
+// containerDockerfile builds the default sandbox image. Its tag carries a hash
+// of the file, so editing it makes the next create rebuild the image.
+//
+//go:embed container.Dockerfile
+var containerDockerfile string
+
+const (
+	// containerLabel marks the containers seven owns; its value is the sprite
+	// name, so List never reports unrelated containers on the same daemon.
+	containerLabel = "seven.sprite"
+	// containerHome is where the persistent home volume is mounted. Custom
+	// images must give their default user this home directory.
+	containerHome = "/home/sprite"
+)
+
+func defaultContainerImage() string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(containerDockerfile))
+	return fmt.Sprintf("seven-sandbox:%08x", h.Sum32())
+}
+
+// containerBackend runs sprites as containers on a local docker or podman
+// daemon, for working offline and for running the integration tests without
+// a Fly account. Each sprite is a long-lived container plus a named volume
+// holding its home directory, so the repo clone, credentials, and installed
+// tooling survive container restarts until the sprite is destroyed.
+type containerBackend struct {
+	runtime string // "docker" or "podman"
+	image   string
+}
+
+func newContainerBackend(runtime, image string) containerBackend {
+	if image == "" {
+		image = defaultContainerImage()
+	}
+	return containerBackend{runtime: runtime, image: image}
+}
+
+func (b containerBackend) Name() string { return b.runtime }
+
+func (b containerBackend) prepare() error {
+	if _, err := exec.LookPath(b.runtime); err != nil {
+		return fmt.Errorf("%s not found in PATH (required by the %s backend)", b.runtime, b.runtime)
+	}
+	return nil
+}
+
+func containerName(name string) string { return "seven-" + name }
+
+func containerVolume(name string) string { return "seven-" + name + "-home" }
+
+func (b containerBackend) Create(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	if err := b.ensureImage(ctx); err != nil {
+		return err
+	}
+	out, err := runCmdOutput(ctx, b.runtime, nil, "run", "--detach",
+		"--name", containerName(name),
+		"--hostname", name,
+		"--label", containerLabel+"="+name,
+		"--restart", "unless-stopped",
+		"--volume", containerVolume(name)+":"+containerHome,
+		b.image, "sleep", "infinity")
+	if err != nil {
+		return fmt.Errorf("%s run %s: %w%s", b.runtime, containerName(name), err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+// ensureImage builds the default image from the embedded Dockerfile the first
+// time it is needed. A custom image is left to the runtime, which pulls it.
+func (b containerBackend) ensureImage(ctx context.Context) error {
+	if err := commandContext(ctx, b.runtime, "image", "inspect", b.image).Run(); err == nil || b.image != defaultContainerImage() {
+		return nil
+	}
+	cmd := commandContext(ctx, b.runtime, "build", "--tag", b.image, "-")
+	cmd.Stdin = strings.NewReader(containerDockerfile)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s build %s: %w%s", b.runtime, b.image, err, gstackOutputTail(string(out)))
+	}
+	return nil
+}
+
+// Destroy removes the container and its home volume.
+func (b containerBackend) Destroy(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	if out, err := runCmdOutput(ctx, b.runtime, nil, "rm", "--force", containerName(name)); err != nil {
+		return fmt.Errorf("%s rm %s: %w%s", b.runtime, containerName(name), err, gstackOutputTail(out))
+	}
+	if out, err := runCmdOutput(ctx, b.runtime, nil, "volume", "rm", "--force", containerVolume(name)); err != nil {
+		return fmt.Errorf("%s volume rm %s: %w%s", b.runtime, containerVolume(name), err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+func (b containerBackend) List(ctx context.Context) ([]string, error) {
+	out, err := runCmdOutput(ctx, b.runtime, nil, "ps", "--all", "--filter", "label="+containerLabel, "--format", "{{.Names}}")
+	if err != nil {
+		return nil, fmt.Errorf("%s ps: %w%s", b.runtime, err, gstackOutputTail(out))
+	}
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "seven-"); ok && spriteNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (b containerBackend) Exec(ctx context.Context, name string, req backend.ExecRequest) error {
+	if err := b.upload(ctx, name, req.Files); err != nil {
+		return err
+	}
+	cmd := commandContext(ctx, b.runtime, b.execArgs(name, req)...)
+	cmd.Stdin = req.Stdin
+	cmd.Stdout = req.Stdout
+	cmd.Stderr = req.Stderr
+	return cmd.Run()
+}
+
+func (b containerBackend) ExecOutput(ctx context.Context, name string, req backend.ExecRequest) (string, error) {
+	if err := b.upload(ctx, name, req.Files); err != nil {
+		return "", err
+	}
+	cmd := commandContext(ctx, b.runtime, b.execArgs(name, req)...)
+	cmd.Stdin = req.Stdin
+	out, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// execArgs builds `docker exec`'s arguments. Unlike the sprite CLI, each
+// variable gets its own --env flag, so values may contain commas.
+func (containerBackend) execArgs(name string, req backend.ExecRequest) []string {
+	args := []string{"exec"}
+	if req.Stdin != nil {
+		args = append(args, "--interactive")
+	}
+	for _, kv := range req.Env {
+		args = append(args, "--env", kv)
+	}
+	args = append(args, containerName(name))
+	return append(args, req.Command...)
+}
+
+// upload streams each file through `exec` rather than `docker cp`, so the
+// copy is owned by the container's user instead of root and keeps the host
+// file's permission bits.
+func (b containerBackend) upload(ctx context.Context, name string, files []backend.File) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	for _, file := range files {
+		f, err := os.Open(file.Local)
+		if err != nil {
+			return err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		cmd := commandContext(ctx, b.runtime, "exec", "--interactive", containerName(name), "sh", "-c",
+			`mkdir -p "$(dirname "$1")" && cat > "$1" && chmod "$2" "$1"`,
+			"sh", file.Remote, fmt.Sprintf("%o", info.Mode().Perm()))
+		cmd.Stdin = f
+		out, err := cmd.CombinedOutput()
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("copy %s to %s: %w%s", file.Local, file.Remote, err, gstackOutputTail(string(out)))
+		}
+	}
+	return nil
+}
+
+func (b containerBackend) CopyFile(ctx context.Context, name, local, remote string) error {
+	return b.upload(ctx, name, []backend.File{{Local: local, Remote: remote}})
+}
+
+// Console opens a login shell, preferring bash so the console bootstrap hook
+// sourced from .bashrc runs as it does on a sprite. A TTY is only requested
+// when seven itself has one.
+func (b containerBackend) Console(ctx context.Context, name string) error {
+	if err := requireSpriteName(name); err != nil {
+		return err
+	}
+	args := []string{"exec", "--interactive"}
+	if stdinIsTerminal() {
+		args = append(args, "--tty")
+	}
+	args = append(args, containerName(name), "sh", "-c", `if command -v bash >/dev/null 2>&1; then exec bash -l; fi; exec sh -l`)
+	return runCmd(ctx, b.runtime, nil, args...)
+}
+
+// Containers have no disk snapshots that cover the home volume, so every
+// checkpoint operation is unsupported.
+func (containerBackend) CreateCheckpoint(context.Context, string, string) (string, error) {
+	return "", backend.ErrUnsupported
+}
+
+func (containerBackend) ListCheckpoints(context.Context, string) ([]backend.Checkpoint, error) {
+	return nil, backend.ErrUnsupported
+}
+
+func (containerBackend) RestoreCheckpoint(context.Context, string, string) error {
+	return backend.ErrUnsupported
+}
+
+func (containerBackend) DeleteCheckpoint(context.Context, string, string) (string, error) {
+	return "", backend.ErrUnsupported
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
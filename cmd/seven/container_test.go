@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"seven/backend"
+)
+
+// createFakeDocker installs a `docker` stub that logs its arguments and keeps
+// the names of running containers in a state file, one per line.
+func createFakeDocker(t *testing.T) (binDir, logPath string) {
+	t.Helper()
+	binDir = t.TempDir()
+	logPath = filepath.Join(binDir, "docker_log")
+	state := filepath.Join(binDir, "docker_state")
+	script := `#!/bin/sh
+state="` + state + `"
+echo "$*" >> "` + logPath + `"
+case "$1" in
+  image) [ -f "$state.image" ] ;;
+  build) cat >/dev/null; : > "$state.image" ;;
+  run)
+    while [ "$#" -gt 0 ] && [ "$1" != "--name" ]; do shift; done
+    echo "$2" >> "$state"
+    ;;
+  ps) [ ! -f "$state" ] || cat "$state" ;;
+  rm)
+    for arg in "$@"; do name="$arg"; done
+    grep -vx "$name" "$state" > "$state.tmp" || true
+    mv "$state.tmp" "$state"
+    ;;
+  exec)
+    case " $* " in *" --interactive "*) cat >/dev/null ;; esac
+    # The cloned repo has no tooling manifest.
+    case "$*" in *"printf 'present'"*) printf 'absent' ;; esac
+    ;;
+esac
+`
+	writeExecutable(t, filepath.Join(binDir, "docker"), script)
+	return binDir, logPath
+}
+
+func TestContainerBackendExecArgs(t *testing.T) {
+	args := newContainerBackend("podman", "").execArgs("hello", backend.ExecRequest{
+		Command: []string{"sh", "-lc", "true"},
+		Env:     []string{"LIST=a,b", "SEVEN_ASSISTANT=codex"},
+		Stdin:   strings.NewReader(""),
+	})
+	want := []string{"exec", "--interactive", "--env", "LIST=a,b", "--env", "SEVEN_ASSISTANT=codex", "seven-hello", "sh", "-lc", "true"}
+	if !slices.Equal(args, want) {
+		t.Fatalf("unexpected exec args:\n got %q\nwant %q", args, want)
+	}
+	if image := newContainerBackend("docker", "").image; !strings.HasPrefix(image, "seven-sandbox:") {
+		t.Fatalf("expected the default image to be seven's own, got %q", image)
+	}
+}
+
+func TestSevenUpWithDockerBackend(t *testing.T) {
+	repo := createTempRepo(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	// The sprite stub stays on PATH so any stray sprite CLI call shows up in
+	// its log.
+	state, spriteLog, cleanup := createFakeSprite(t)
+	defer cleanup()
+	dockerDir, dockerLog := createFakeDocker(t)
+	env := []string{
+		"SEVEN_BACKEND=docker",
+		"PATH=" + dockerDir + string(os.PathListSeparator) + filepath.Dir(state) + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+	runSeven := func(args ...string) string {
+		cmd := exec.Command(testSevenBin, args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "HOME="+t.TempDir(), "SPRITE_STATE="+state, "SPRITE_LOG="+spriteLog)
+		cmd.Env = append(cmd.Env, env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("seven %s failed: %v\n%s", args[0], err, out)
+		}
+		return string(out)
+	}
+
+	runSeven("up", "--no-tui", "--no-console")
+	data, err := os.ReadFile(dockerLog)
+	if err != nil {
+		t.Fatalf("expected docker log: %v", err)
+	}
+	log := string(data)
+	for _, want := range []string{
+		"build --tag seven-sandbox:",
+		"run --detach --name seven-hello --hostname hello --label seven.sprite=hello --restart unless-stopped --volume seven-hello-home:/home/sprite seven-sandbox:",
+		"exec seven-hello ",
+	} {
+		if !strings.Contains(log, want) {
+			t.Fatalf("expected %q in docker log, got:\n%s", want, log)
+		}
+	}
+	if data, err := os.ReadFile(spriteLog); err == nil && len(data) > 0 {
+		t.Fatalf("expected no sprite CLI calls with the docker backend, got:\n%s", data)
+	}
+
+	if out := runSeven("list"); !strings.Contains(out, "hello") {
+		t.Fatalf("expected list to show the container, got:\n%s", out)
+	}
+	runSeven("destroy")
+	data, _ = os.ReadFile(dockerLog)
+	if log := string(data); !strings.Contains(log, "rm --force seven-hello") || !strings.Contains(log, "volume rm --force seven-hello-home") {
+		t.Fatalf("expected destroy to remove the container and its home volume, got:\n%s", log)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"seven/backend"
+)
+
+// devProxyRestartDelay is the initial back-off before restarting a dropped
+// proxy. It doubles on every quick failure up to devProxyMaxRestartDelay and
+// resets once a proxy has stayed up for devProxyHealthyAfter.
+var (
+	devProxyRestartDelay    = time.Second
+	devProxyMaxRestartDelay = 30 * time.Second
+	devProxyHealthyAfter    = 30 * time.Second
+)
+
+// requirePortForwarder returns the active backend's port forwarding, exiting
+// when the backend has none.
+func requirePortForwarder(command string) backend.PortForwarder {
+	forwarder, ok := activeBackend.(backend.PortForwarder)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "seven %s failed: the %s backend cannot forward ports\n", command, activeBackend.Name())
+		os.Exit(1)
+	}
+	return forwarder
+}
+
+// devPort forwards Local on the host to Remote inside the sprite.
+type devPort struct {
+	Local  int
+	Remote int
+}
+
+// spec renders the port in `sprite proxy` syntax: "3000" or "8080:3000".
+func (p devPort) spec() string {
+	if p.Local == p.Remote {
+		return strconv.Itoa(p.Local)
+	}
+	return fmt.Sprintf("%d:%d", p.Local, p.Remote)
+}
+
+func parseDevPort(value string) (devPort, error) {
+	local, remote, mapped := strings.Cut(strings.TrimSpace(value), ":")
+	l, err := parsePortNumber(local)
+	if err != nil {
+		return devPort{}, fmt.Errorf("invalid port %q: %w", value, err)
+	}
+	if !mapped {
+		return devPort{Local: l, Remote: l}, nil
+	}
+	r, err := parsePortNumber(remote)
+	if err != nil {
+		return devPort{}, fmt.Errorf("invalid port %q: %w", value, err)
+	}
+	return devPort{Local: l, Remote: r}, nil
+}
+
+func parsePortNumber(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 65535 {
+		return 0, errors.New("ports must be between 1 and 65535 (use LOCAL:REMOTE to remap)")
+	}
+	return n, nil
+}
+
+// uniqueDevPorts rejects two forwards competing for the same local port.
+func uniqueDevPorts(ports []devPort) error {
+	seen := map[int]bool{}
+	for _, port := range ports {
+		if seen[port.Local] {
+			return fmt.Errorf("local port %d is forwarded more than once", port.Local)
+		}
+		seen[port.Local] = true
+	}
+	return nil
+}
+
+func cmdDev(args []string) {
+	fs := flag.NewFlagSet("dev", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "forward ports of a specific sprite name")
+	_ = fs.Parse(args)
+
+	var ports []devPort
+	for _, arg := range fs.Args() {
+		port, err := parseDevPort(arg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seven dev failed: %v\n", err)
+			os.Exit(1)
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) == 0 {
+		projectCfg, err := loadProjectConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seven dev failed: %v\n", err)
+			os.Exit(1)
+		}
+		ports = projectCfg.Dev.Ports
+	}
+	if len(ports) == 0 {
+		fmt.Fprintf(os.Stderr, "seven dev failed: no ports to forward; pass them (seven dev 3000 8080:80) or declare [dev] ports in %s\n", projectConfigFileName)
+		os.Exit(1)
+	}
+	if err := uniqueDevPorts(ports); err != nil {
+		fmt.Fprintf(os.Stderr, "seven dev failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	forwarder := requirePortForwarder("dev")
+	name := resolveExistingSprite(ctx, "dev", upOptions{SpriteName: strings.TrimSpace(*spriteName)})
+
+	logger := func(msg string) { fmt.Println(formatStyledBulletLog(msg)) }
+	runDevProxies(ctx, forwarder, name, ports, logger)
+	logger("[seven dev] stopped all port forwards")
+}
+
+// runDevProxies supervises one forward (a `sprite proxy` for the sprite
+// backend) per port until ctx is cancelled. Cancellation stops every forward
+// before returning.
+func runDevProxies(ctx context.Context, forwarder backend.PortForwarder, spriteName string, ports []devPort, logger func(string)) {
+	var wg sync.WaitGroup
+	for _, port := range ports {
+		wg.Add(1)
+		go func(port devPort) {
+			defer wg.Done()
+			superviseDevProxy(ctx, forwarder, spriteName, port, logger)
+		}(port)
+	}
+	logger(fmt.Sprintf("[seven dev] forwarding %d port(s) from %s; press Ctrl-C to stop", len(ports), spriteName))
+	wg.Wait()
+}
+
+func superviseDevProxy(ctx context.Context, forwarder backend.PortForwarder, spriteName string, port devPort, logger func(string)) {
+	delay := devProxyRestartDelay
+	for {
+		logger(fmt.Sprintf("[seven dev] localhost:%d -> %s:%d", port.Local, spriteName, port.Remote))
+		started := time.Now()
+		err := forwarder.Forward(ctx, spriteName, port.Local, port.Remote)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) >= devProxyHealthyAfter {
+			delay = devProxyRestartDelay
+		}
+		reason := "exited"
+		if err != nil {
+			reason = err.Error()
+		}
+		logger(fmt.Sprintf("[seven dev] proxy for port %d dropped (%s); restarting in %s", port.Local, reason, delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > devProxyMaxRestartDelay {
+			delay = devProxyMaxRestartDelay
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startSevenDev starts `seven dev` in repo against a fake sprite and returns the
+// running command and a buffer collecting its output.
+func startSevenDev(t *testing.T, repo, state, logPath string, extraEnv []string, args ...string) (*exec.Cmd, *strings.Builder) {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, append([]string{"dev"}, args...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	cmd.Env = append(cmd.Env, extraEnv...)
+	output := &strings.Builder{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start seven dev: %v", err)
+	}
+	return cmd, output
+}
+
+// waitForLog polls the fake sprite log until every want string appears at least
+// count times or the deadline passes.
+func waitForLog(t *testing.T, logPath string, count int, wants ...string) string {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		log := string(data)
+		ok := true
+		for _, want := range wants {
+			if strings.Count(log, want) < count {
+				ok = false
+			}
+		}
+		if ok {
+			return log
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q in log: %s", wants, log)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestSevenDevForwardsConfiguredPortsUntilInterrupted(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte("[dev]\nports = [3000, 5173]\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+
+	cmd, output := startSevenDev(t, repo, state, logPath, nil)
+	waitForLog(t, logPath, 1, "proxy -s hello 3000", "proxy -s hello 5173")
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatalf("interrupt seven dev: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("seven dev should exit cleanly on interrupt: %v\n%s", err, output)
+	}
+	if !strings.Contains(output.String(), "stopped all port forwards") {
+		t.Fatalf("expected teardown message, got: %s", output)
+	}
+}
+
+func TestSevenDevRestartsDroppedProxy(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	// Command-line ports replace the configured ones.
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte("[dev]\nports = [3000]\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+	cmd, output := startSevenDev(t, repo, state, logPath, []string{"SPRITE_PROXY_DROP_ONCE=1"}, "8080:80")
+	log := waitForLog(t, logPath, 2, "proxy -s hello 8080:80")
+	_ = cmd.Process.Signal(os.Interrupt)
+	_ = cmd.Wait()
+	if strings.Contains(log, "proxy -s hello 3000") {
+		t.Fatalf("command-line ports must replace .seven.toml ports, got: %s", log)
+	}
+	if !strings.Contains(output.String(), "proxy for port 8080 dropped") {
+		t.Fatalf("expected restart to be reported, got: %s", output)
+	}
+}
+
+func TestSevenDevRequiresPorts(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	cmd, output := startSevenDev(t, repo, state, logPath, nil)
+	if err := cmd.Wait(); err == nil || !strings.Contains(output.String(), "no ports to forward") {
+		t.Fatalf("expected missing ports to fail, err=%v output=%s", err, output)
+	}
+}
+
+func TestParseDevPort(t *testing.T) {
+	for input, want := range map[string]devPort{
+		"3000":      {Local: 3000, Remote: 3000},
+		"8080:80":   {Local: 8080, Remote: 80},
+		" 5173 ":    {Local: 5173, Remote: 5173},
+		"65535:443": {Local: 65535, Remote: 443},
+	} {
+		got, err := parseDevPort(input)
+		if err != nil || got != want {
+			t.Fatalf("parseDevPort(%q) = %+v, %v; want %+v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"", "0", "70000", "http", "80:", ":80", "1:2:3"} {
+		if _, err := parseDevPort(input); err == nil {
+			t.Fatalf("expected parseDevPort(%q) to fail", input)
+		}
+	}
+	if err := uniqueDevPorts([]devPort{{3000, 3000}, {3000, 4000}}); err == nil {
+		t.Fatal("expected duplicate local port to fail")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"seven/backend"
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envFlags collects repeated --env KEY=VAL flags.
+type envFlags []string
+
+func (e *envFlags) String() string { return strings.Join(*e, " ") }
+
+func (e *envFlags) Set(value string) error {
+	name, _, ok := strings.Cut(value, "=")
+	if !ok || !envNamePattern.MatchString(name) {
+		return fmt.Errorf("expected KEY=VAL, got %q", value)
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+// execInRepoScript is the sh -c program seven exec runs: $1 is the repo
+// directory relative to $HOME and the remaining arguments are the command.
+// The command is exec'd, so its exit status is the sprite exec status.
+const execInRepoScript = `repo="$HOME/$1"
+shift
+if ! cd "$repo" 2>/dev/null; then
+  echo "seven exec: $repo not found in the sprite (run 'seven up' first)" >&2
+  exit 1
+fi
+exec "$@"`
+
+func cmdExec(args []string) {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "run in a specific sprite name")
+	var env envFlags
+	fs.Var(&env, "env", "set an environment variable for the command (KEY=VAL, repeatable)")
+
+	ordinal, args := parseSpriteOrdinal("exec", args)
+	_ = fs.Parse(args)
+	command := fs.Args()
+	if len(command) == 0 {
+		fmt.Fprintln(os.Stderr, "seven exec failed: missing command (usage: seven exec [N] [--env KEY=VAL] -- cmd...)")
+		os.Exit(1)
+	}
+	if ordinal > 0 && strings.TrimSpace(*spriteName) != "" {
+		fmt.Fprintln(os.Stderr, "seven exec failed: sprite number cannot be combined with --sprite")
+		os.Exit(1)
+	}
+
+	// The remote command owns Ctrl-C, as in a local shell, so seven does not
+	// interrupt it.
+	ctx := context.Background()
+	name := resolveExistingSprite(ctx, "exec", upOptions{SpriteName: strings.TrimSpace(*spriteName), SiblingOrdinal: ordinal})
+	os.Exit(runExec(ctx, name, env, command))
+}
+
+// runExec runs command in the sprite's cloned repo with stdio attached and
+// returns the exit code to propagate to the host.
+func runExec(ctx context.Context, spriteName string, env []string, command []string) int {
+	args := []string{"sh", "-lc", execInRepoScript, "seven-exec", spriteFamilyBase(spriteName)}
+	if len(env) > 0 {
+		// Pass variables through env(1) rather than sprite's comma-separated
+		// -env list so values may contain commas.
+		args = append(append(args, "env"), env...)
+	}
+	args = append(args, command...)
+
+	err := spriteExec(ctx, spriteName, nil, false, args...)
+	code, exited := backend.ExitCode(err)
+	switch {
+	case err == nil:
+		return 0
+	case exited && code > 0:
+		return code
+	default:
+		fmt.Fprintf(os.Stderr, "seven exec failed: %v\n", err)
+		return 1
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runSevenExec runs `seven exec ...` in repo against a fake sprite that runs
+// commands for real in spriteHome, and returns stdout, stderr, and exit code.
+func runSevenExec(t *testing.T, repo, state, logPath, spriteHome, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, append([]string{"exec"}, args...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_EXEC_HOME="+spriteHome,
+	)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("run seven exec: %v", err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+func TestSevenExecRunsInSiblingRepoDir(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\nhello-02\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(spriteHome, "hello"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runSevenExec(t, repo, state, logPath, spriteHome, "from stdin\n",
+		"2", "--env", "GREETING=hi, there", "--", "sh", "-c", `pwd; printf '%s\n' "$GREETING"; cat; exit 3`)
+	if code != 3 {
+		t.Fatalf("expected the command's exit code, got %d\nstdout=%s\nstderr=%s", code, stdout, stderr)
+	}
+	want := filepath.Join(spriteHome, "hello") + "\nhi, there\nfrom stdin\n"
+	if stdout != want {
+		t.Fatalf("unexpected output %q, want %q", stdout, want)
+	}
+	logData, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logData), "exec -s hello-02 --") {
+		t.Fatalf("expected exec in sibling hello-02, got: %s", logData)
+	}
+}
+
+func TestSevenExecFailsWithoutClonedRepo(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	_, stderr, code := runSevenExec(t, repo, state, logPath, t.TempDir(), "", "true")
+	if code == 0 || !strings.Contains(stderr, "not found in the sprite") {
+		t.Fatalf("expected missing repo dir to fail, code=%d stderr=%s", code, stderr)
+	}
+}
+
+func TestSevenExecRejectsInvalidArgs(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "missing command"},
+		{[]string{"--env", "1BAD=x", "--", "true"}, "expected KEY=VAL"},
+		{[]string{"2", "--sprite", "other", "--", "true"}, "cannot be combined with --sprite"},
+	} {
+		_, stderr, code := runSevenExec(t, repo, state, logPath, t.TempDir(), "", tc.args...)
+		if code == 0 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("seven exec %q: expected %q, code=%d stderr=%s", tc.args, tc.want, code, stderr)
+		}
+	}
+	if logData, _ := os.ReadFile(logPath); strings.Contains(string(logData), "exec -s") {
+		t.Fatalf("invalid arguments must not reach the sprite, got: %s", logData)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"seven/backend"
+)
+
+// syncMergePolicy says how a host file combines with the sprite's own copy.
+type syncMergePolicy int
+
+const (
+	// syncReplace overwrites the sprite's copy. Credentials use it: the host
+	// token is the freshest, and a stale token in the sprite must go.
+	syncReplace syncMergePolicy = iota
+	// syncMergeJSON deep-merges the host's keys into the sprite's JSON (see
+	// deepMergeJSON), so keys only the sprite has survive.
+	syncMergeJSON
+)
+
+// syncFile is one host file to install in the sprite.
+type syncFile struct {
+	Label     string // names the file in logs, e.g. "claude config"
+	Assistant string // assistant the file belongs to, e.g. "cursor"
+	Tool      string // sprite command the file configures; skipped when missing
+	Local     string // host path
+	Stage     string // upload path in the sprite, removed once installed
+	Dest      string // install path relative to the sprite's $HOME
+	Mode      os.FileMode
+	Merge     syncMergePolicy
+	Omit      []string // top-level JSON keys a dedicated sync handles instead
+}
+
+// hostAssistantSyncFiles plans the assistant config and credential files of
+// state for syncHostFilesToSprite. macOS Keychain credentials are written to
+// a temporary file, which cleanup removes.
+func hostAssistantSyncFiles(state hostAssistantState, phase string, opts upOptions) (files []syncFile, cleanup func()) {
+	cleanup = func() {}
+	if state.ClaudeConfigPath != "" {
+		files = append(files, syncFile{Label: "claude config", Assistant: "claude", Tool: "claude", Local: state.ClaudeConfigPath,
+			Stage: "/tmp/host-claude-settings.json", Dest: ".claude/settings.json", Mode: 0o600, Merge: syncMergeJSON})
+	}
+	if state.ClaudeAuthPath != "" {
+		files = append(files, syncFile{Label: "claude auth", Assistant: "claude", Tool: "claude", Local: state.ClaudeAuthPath,
+			Stage: "/tmp/host-claude-auth.json", Dest: ".claude.json", Mode: 0o600, Merge: syncMergeJSON,
+			// Host MCP servers may not run in the sprite; syncMCPServers
+			// adds the ones that do.
+			Omit: []string{"mcpServers"}})
+	}
+	if state.ClaudeCredentials.present() {
+		credentialsPath, remove, err := claudeCredentialsFile(state.ClaudeCredentials)
+		if err != nil {
+			opts.Logger(fmt.Sprintf("%s claude credentials setup failed: %v", phase, err))
+		} else {
+			cleanup = remove
+			files = append(files, syncFile{Label: "claude credentials", Assistant: "claude", Tool: "claude", Local: credentialsPath,
+				Stage: "/tmp/host-claude-credentials.json", Dest: ".claude/.credentials.json", Mode: 0o600, Merge: syncReplace})
+		}
+	}
+	if state.CodexConfigPath != "" {
+		files = append(files, syncFile{Label: "codex config", Assistant: "codex", Tool: "codex", Local: state.CodexConfigPath,
+			Stage: "/tmp/host-codex-config.toml", Dest: ".codex/config.toml", Mode: 0o600, Merge: syncReplace})
+	}
+	if state.CodexAuthPath != "" {
+		files = append(files, syncFile{Label: "codex auth", Assistant: "codex", Tool: "codex", Local: state.CodexAuthPath,
+			Stage: "/tmp/host-codex-auth.json", Dest: ".codex/auth.json", Mode: 0o600, Merge: syncReplace})
+	}
+	if state.CursorConfigPath != "" {
+		files = append(files, syncFile{Label: "cursor config", Assistant: "cursor", Tool: "cursor-agent", Local: state.CursorConfigPath,
+			Stage: "/tmp/host-cursor-config.json", Dest: ".cursor/cli-config.json", Mode: 0o600, Merge: syncMergeJSON})
+	}
+	if state.CursorAuthPath != "" {
+		files = append(files, syncFile{Label: "cursor auth", Assistant: "cursor", Tool: "cursor-agent", Local: state.CursorAuthPath,
+			Stage: "/tmp/host-cursor-auth.json", Dest: ".config/cursor/auth.json", Mode: 0o600, Merge: syncReplace})
+	}
+	if state.GeminiConfigPath != "" {
+		files = append(files, syncFile{Label: "gemini config", Assistant: "gemini", Tool: "gemini", Local: state.GeminiConfigPath,
+			Stage: "/tmp/host-gemini-settings.json", Dest: ".gemini/settings.json", Mode: 0o600, Merge: syncMergeJSON})
+	}
+	if state.GeminiAuthPath != "" {
+		files = append(files, syncFile{Label: "gemini auth", Assistant: "gemini", Tool: "gemini", Local: state.GeminiAuthPath,
+			Stage: "/tmp/host-gemini-auth.json", Dest: ".gemini/oauth_creds.json", Mode: 0o600, Merge: syncReplace})
+	}
+	return files, cleanup
+}
+
+// claudeCredentialsFile returns a host file holding the Claude Code OAuth
+// credentials: the credentials file itself on Linux, or a 0600 temporary copy
+// extracted from the macOS Keychain.
+func claudeCredentialsFile(src claudeCredentialsSource) (string, func(), error) {
+	if !src.Keychain {
+		return src.FilePath, func() {}, nil
+	}
+	data, err := extractClaudeKeychainCredentials()
+	if err != nil {
+		return "", nil, fmt.Errorf("reading claude credentials from keychain: %w", err)
+	}
+	tmpFile, err := os.CreateTemp("", "seven-claude-credentials-*.json")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { os.Remove(tmpFile.Name()) }
+	if _, err := tmpFile.WriteString(data); err != nil {
+		tmpFile.Close()
+		remove()
+		return "", nil, err
+	}
+	if err := tmpFile.Chmod(0o600); err != nil {
+		tmpFile.Close()
+		remove()
+		return "", nil, err
+	}
+	tmpFile.Close()
+	return tmpFile.Name(), remove, nil
+}
+
+// syncHostFilesToSprite installs files in two sprite execs however many
+// there are: one surveys which tools the sprite has and reads the copies the
+// JSON merges need, and one uploads every file (a -file argument each for the
+// sprite backend) and installs them with a single script. A file whose tool
+// is missing, or whose merge fails, is logged and skipped. It returns the
+// files it installed.
+func syncHostFilesToSprite(ctx context.Context, spriteName string, files []syncFile, phase string, opts upOptions) ([]syncFile, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", syncSurveyScript(files))
+	if err != nil {
+		return nil, fmt.Errorf("inspect sprite for file sync: %w%s", err, gstackOutputTail(out))
+	}
+	survey := parseSyncSurvey(out)
+
+	var uploads []backend.File
+	var installs []syncFile
+	for i, f := range files {
+		if survey.missing[f.Tool] {
+			opts.Logger(fmt.Sprintf("%s %s not found in sprite, skipping %s sync", phase, f.Tool, f.Label))
+			continue
+		}
+		local := f.Local
+		if f.Merge == syncMergeJSON {
+			merged, cleanup, err := mergedJSONForSprite(f.Local, survey.existing[i], f.Omit)
+			if err != nil {
+				opts.Logger(fmt.Sprintf("%s %s setup failed: %v", phase, f.Label, err))
+				continue
+			}
+			if cleanup != nil {
+				defer cleanup()
+			}
+			local = merged
+		}
+		opts.Logger(fmt.Sprintf("%s syncing %s into sprite", phase, f.Label))
+		uploads = append(uploads, backend.File{Local: local, Remote: f.Stage})
+		installs = append(installs, f)
+	}
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	if err := spriteExecWithFiles(ctx, spriteName, uploads, opts.QuietExternal, "sh", "-lc", syncInstallScript(installs)); err != nil {
+		return nil, err
+	}
+	return installs, nil
+}
+
+// syncSurveyScript prints "missing TOOL" for each tool the sprite lacks and
+// "existing INDEX BASE64" for each merge target the sprite already has. A
+// sprite with every tool and none of the files prints nothing.
+func syncSurveyScript(files []syncFile) string {
+	var lines []string
+	probed := map[string]bool{}
+	for i, f := range files {
+		if !probed[f.Tool] {
+			probed[f.Tool] = true
+			lines = append(lines, fmt.Sprintf("command -v %s >/dev/null 2>&1 || echo 'missing %s'", f.Tool, f.Tool))
+		}
+		if f.Merge == syncMergeJSON {
+			lines = append(lines, fmt.Sprintf(`if [ -s "$HOME/%s" ]; then printf 'existing %d '; base64 < "$HOME/%s" | tr -d '\n'; echo; fi`, f.Dest, i, f.Dest))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type syncSurvey struct {
+	missing  map[string]bool
+	existing map[int]string // sprite copies of merge targets, by file index
+}
+
+// parseSyncSurvey reads syncSurveyScript's output. A copy that does not
+// decode is treated as absent, so the host file replaces it.
+func parseSyncSurvey(out string) syncSurvey {
+	survey := syncSurvey{missing: map[string]bool{}, existing: map[int]string{}}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[0] == "missing":
+			survey.missing[fields[1]] = true
+		case len(fields) == 3 && fields[0] == "existing":
+			index, err := strconv.Atoi(fields[1])
+			if err != nil {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(fields[2])
+			if err != nil {
+				continue
+			}
+			survey.existing[index] = string(data)
+		}
+	}
+	return survey
+}
+
+// syncInstallScript installs every staged file with its mode, creating
+// parent directories as 0700. It carries on past a file it cannot install,
+// so one bad file does not cost the others, and then fails naming them.
+func syncInstallScript(files []syncFile) string {
+	var b strings.Builder
+	b.WriteString("failed=\n")
+	for _, f := range files {
+		install := fmt.Sprintf(`install -m %o %s "$HOME/%s"`, f.Mode, f.Stage, f.Dest)
+		if dir := path.Dir(f.Dest); dir != "." {
+			install = fmt.Sprintf(`install -d -m 700 "$HOME/%s" && %s`, dir, install)
+		}
+		fmt.Fprintf(&b, "%s || failed=\"$failed %s\"\nrm -f %s\n", install, f.Dest, f.Stage)
+	}
+	b.WriteString(`if [ -n "$failed" ]; then echo "could not install:$failed" >&2; exit 1; fi`)
+	return b.String()
+}
+
+// mergedJSONForSprite deep-merges the host JSON file's values, less its omit
+// keys, into the sprite's existing copy, spriteData, so that sprite-only keys
+// are preserved, and returns the path to the file that should be copied into
+// the sprite. When there is nothing to merge into and nothing to omit, or the
+// merge cannot be performed, it returns the original hostPath unchanged. If a
+// temporary file is created the returned cleanup func removes it.
+func mergedJSONForSprite(hostPath, spriteData string, omit []string) (mergedPath string, cleanup func(), err error) {
+	hostData, err := os.ReadFile(hostPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading host config: %w", err)
+	}
+	var hostJSON map[string]interface{}
+	if err := json.Unmarshal(hostData, &hostJSON); err != nil {
+		return hostPath, nil, nil
+	}
+	omitted := false
+	for _, key := range omit {
+		if _, ok := hostJSON[key]; ok {
+			delete(hostJSON, key)
+			omitted = true
+		}
+	}
+
+	spriteJSON := map[string]interface{}{}
+	if strings.TrimSpace(spriteData) == "" {
+		if !omitted {
+			return hostPath, nil, nil
+		}
+	} else if err := json.Unmarshal([]byte(spriteData), &spriteJSON); err != nil {
+		if !omitted {
+			return hostPath, nil, nil
+		}
+		spriteJSON = map[string]interface{}{}
+	}
+
+	merged := deepMergeJSON(spriteJSON, hostJSON)
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err == nil {
+		var path string
+		if path, cleanup, err = writeSyncTemp("seven-merged-*.json", data); err == nil {
+			return path, cleanup, nil
+		}
+	}
+	// The host file itself would carry the omitted keys along.
+	if omitted {
+		return "", nil, fmt.Errorf("writing merged config: %w", err)
+	}
+	return hostPath, nil, nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"seven/backend/fake"
+)
+
+func TestSyncHostAssistantStateUsesOneUploadExec(t *testing.T) {
+	home := t.TempDir()
+	write := func(rel, contents string) string {
+		path := filepath.Join(home, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	state := hostAssistantState{
+		ClaudeConfigPath:  write(".claude/settings.json", `{"theme":"dark-ansi"}`),
+		ClaudeAuthPath:    write(".claude.json", `{"oauthAccount":{"emailAddress":"dev@example.com"}}`),
+		ClaudeCredentials: claudeCredentialsSource{FilePath: write(".claude/.credentials.json", `{"claudeAiOauth":{}}`)},
+		CodexConfigPath:   write(".codex/config.toml", "model = \"o3\"\n"),
+		CodexAuthPath:     write(".codex/auth.json", `{"tokens":{}}`),
+	}
+
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	existing := base64.StdEncoding.EncodeToString([]byte(`{"projects":{"/home/sprite":{}}}`))
+	b.OnExec(fake.CommandContains("echo 'missing claude'"), fake.Reply{Stdout: "missing codex\nexisting 1 " + existing + "\n"})
+	b.OnExec(fake.CommandContains("claude auth status"), fake.Reply{Stdout: `{"loggedIn":true}`})
+
+	var logs []string
+	opts := upOptions{QuietExternal: true, Logger: func(msg string) { logs = append(logs, msg) }}
+	state = syncHostAssistantState(context.Background(), "hello", state, "[seven init]", opts)
+	if state.PreferredAssistant != "claude" {
+		t.Fatalf("expected claude to be preferred, got %q", state.PreferredAssistant)
+	}
+
+	var uploads int
+	for _, call := range b.Execs() {
+		if len(call.Files) == 0 {
+			continue
+		}
+		uploads++
+		if len(call.Files) != 3 {
+			t.Fatalf("expected the three claude files in one upload, got %+v", call.Files)
+		}
+		for _, want := range []string{`"$HOME/.claude/settings.json"`, `"$HOME/.claude.json"`, `"$HOME/.claude/.credentials.json"`} {
+			if !strings.Contains(call.Script(), want) {
+				t.Fatalf("expected the install script to install %s, got:\n%s", want, call.Script())
+			}
+		}
+	}
+	if uploads != 1 {
+		t.Fatalf("expected exactly one upload exec, got %d", uploads)
+	}
+
+	sprite, _ := b.Sprite("hello")
+	auth, ok := sprite.Files["/tmp/host-claude-auth.json"]
+	if !ok {
+		t.Fatalf("expected the claude auth upload, got %v", sprite.Files)
+	}
+	var merged map[string]interface{}
+	if err := json.Unmarshal(auth.Contents, &merged); err != nil {
+		t.Fatalf("uploaded auth is not JSON: %v", err)
+	}
+	if merged["projects"] == nil || merged["oauthAccount"] == nil {
+		t.Fatalf("expected host keys merged into the sprite copy, got %v", merged)
+	}
+	joined := strings.Join(logs, "\n")
+	if !strings.Contains(joined, "codex not found in sprite, skipping codex auth sync") {
+		t.Fatalf("expected the codex files to be skipped, got:\n%s", joined)
+	}
+}
+
+func TestSyncHostAssistantStateSyncsCursorAndGemini(t *testing.T) {
+	home := t.TempDir()
+	write := func(rel, contents string) string {
+		path := filepath.Join(home, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	state := hostAssistantState{
+		CursorConfigPath: write(".cursor/cli-config.json", `{"permissions":{}}`),
+		CursorAuthPath:   write(".config/cursor/auth.json", `{"accessToken":"x"}`),
+		GeminiConfigPath: write(".gemini/settings.json", `{"selectedAuthType":"oauth-personal"}`),
+		GeminiAuthPath:   write(".gemini/oauth_creds.json", `{"refresh_token":"x"}`),
+	}
+
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	existing := base64.StdEncoding.EncodeToString([]byte(`{"theme":"GitHub"}`))
+	b.OnExec(fake.CommandContains("echo 'missing gemini'"), fake.Reply{Stdout: "missing cursor-agent\nexisting 2 " + existing + "\n"})
+
+	var logs []string
+	opts := upOptions{QuietExternal: true, Logger: func(msg string) { logs = append(logs, msg) }}
+	state = syncHostAssistantState(context.Background(), "hello", state, "[seven init]", opts)
+	if state.PreferredAssistant != "cursor" {
+		t.Fatalf("expected cursor to be preferred over gemini, got %q", state.PreferredAssistant)
+	}
+
+	sprite, _ := b.Sprite("hello")
+	if _, ok := sprite.Files["/tmp/host-gemini-auth.json"]; !ok {
+		t.Fatalf("expected the gemini auth upload, got %v", sprite.Files)
+	}
+	if _, ok := sprite.Files["/tmp/host-cursor-auth.json"]; ok {
+		t.Fatal("expected no cursor upload into a sprite without cursor-agent")
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(sprite.Files["/tmp/host-gemini-settings.json"].Contents, &settings); err != nil {
+		t.Fatalf("uploaded gemini settings are not JSON: %v", err)
+	}
+	if settings["theme"] == nil || settings["selectedAuthType"] == nil {
+		t.Fatalf("expected host keys merged into the sprite's gemini settings, got %v", settings)
+	}
+	joined := strings.Join(logs, "\n")
+	if !strings.Contains(joined, "cursor-agent not found in sprite, skipping cursor auth sync") {
+		t.Fatalf("expected the cursor files to be skipped, got:\n%s", joined)
+	}
+}
+
+func TestParseSyncSurvey(t *testing.T) {
+	out := "missing codex\nexisting 0 " + base64.StdEncoding.EncodeToString([]byte(`{"a":1}`)) + "\nexisting 2 !!!\nnoise\n"
+	survey := parseSyncSurvey(out)
+	if !survey.missing["codex"] || survey.missing["claude"] {
+		t.Fatalf("unexpected missing tools: %v", survey.missing)
+	}
+	if survey.existing[0] != `{"a":1}` {
+		t.Fatalf("unexpected existing copy: %q", survey.existing[0])
+	}
+	if _, ok := survey.existing[2]; ok {
+		t.Fatal("expected an undecodable copy to be treated as absent")
+	}
+}
+
+func TestSyncInstallScriptKeepsGoingPastFailures(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(home, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	stage := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// A file where .codex should be makes the codex install fail.
+	if err := os.WriteFile(filepath.Join(home, ".codex"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files := []syncFile{
+		{Stage: stage("auth.json"), Dest: ".codex/auth.json", Mode: 0o600},
+		{Stage: stage("claude.json"), Dest: ".claude.json", Mode: 0o600},
+	}
+	cmd := exec.Command("sh", "-c", syncInstallScript(files))
+	cmd.Env = []string{"HOME=" + home, "PATH=" + os.Getenv("PATH")}
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "could not install: .codex/auth.json") {
+		t.Fatalf("expected the codex install to be reported, got %v\n%s", err, out)
+	}
+	info, err := os.Stat(filepath.Join(home, ".claude.json"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected .claude.json installed as 0600, got %v, %v", info, err)
+	}
+	if _, err := os.Stat(files[1].Stage); !os.IsNotExist(err) {
+		t.Fatalf("expected the staged file to be removed, got %v", err)
+	}
+}
+
+func TestSyncSurveyScriptReportsToolsAndMergeTargets(t *testing.T) {
+	home := t.TempDir()
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "claude"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(`{"projects":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files := []syncFile{
+		{Tool: "claude", Dest: ".claude/settings.json", Merge: syncMergeJSON},
+		{Tool: "claude", Dest: ".claude.json", Merge: syncMergeJSON},
+		{Tool: "seven-missing-tool", Dest: ".codex/auth.json", Merge: syncReplace},
+	}
+	cmd := exec.Command("sh", "-c", syncSurveyScript(files))
+	cmd.Env = []string{"HOME=" + home, "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("survey failed: %v\n%s", err, out)
+	}
+	survey := parseSyncSurvey(string(out))
+	if survey.missing["claude"] || !survey.missing["seven-missing-tool"] {
+		t.Fatalf("unexpected missing tools %v from:\n%s", survey.missing, out)
+	}
+	if _, ok := survey.existing[0]; ok || survey.existing[1] != `{"projects":{}}` {
+		t.Fatalf("unexpected existing copies %v from:\n%s", survey.existing, out)
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ideDefaultPort is where openvscode-server listens inside the sprite and, by
+// default, the local port it is forwarded to.
+const ideDefaultPort = 3939
+
+var ideVersionPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+$`)
+
+// idePin is the openvscode-server release seven ide installs. Like archive
+// rows in the tooling manifest it is pinned to an exact version and a SHA-256
+// per architecture; seven refuses to install anything it cannot verify.
+type idePin struct {
+	Version      string
+	SHA256X86_64 string
+	SHA256Arm64  string
+}
+
+func (p idePin) configured() bool {
+	return p.Version != "" || p.SHA256X86_64 != "" || p.SHA256Arm64 != ""
+}
+
+func (p idePin) validate() error {
+	if !ideVersionPattern.MatchString(p.Version) {
+		return fmt.Errorf("[ide] version must be an exact openvscode-server release like 1.2.3, got %q", p.Version)
+	}
+	if !sha256Pattern.MatchString(p.SHA256X86_64) || !sha256Pattern.MatchString(p.SHA256Arm64) {
+		return fmt.Errorf("[ide] sha256_x86_64 and sha256_arm64 must be lowercase SHA-256 hex digests of the release archives")
+	}
+	return nil
+}
+
+func cmdIde(args []string) {
+	fs := flag.NewFlagSet("ide", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "open the IDE in a specific sprite name")
+	localPort := fs.Int("port", 0, "local port to forward the IDE to (default from .seven.toml, else 3939)")
+	open := fs.Bool("open", false, "open the IDE URL in the default browser")
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "seven ide failed: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+
+	projectCfg, err := loadProjectConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven ide failed: %v\n", err)
+		os.Exit(1)
+	}
+	pin := projectCfg.Ide.Pin
+	if !pin.configured() {
+		fmt.Fprintf(os.Stderr, "seven ide failed: pin openvscode-server in %s with [ide] version, sha256_x86_64, and sha256_arm64\n", projectConfigFileName)
+		os.Exit(1)
+	}
+	port := projectCfg.Ide.Port
+	if *localPort != 0 {
+		port = *localPort
+	}
+	if port < 1 || port > 65535 {
+		fmt.Fprintln(os.Stderr, "seven ide failed: --port must be between 1 and 65535")
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	forwarder := requirePortForwarder("ide")
+	name := resolveExistingSprite(ctx, "ide", upOptions{SpriteName: strings.TrimSpace(*spriteName)})
+	logger := func(msg string) { fmt.Println(formatStyledBulletLog(msg)) }
+
+	logger(fmt.Sprintf("[seven ide] ensuring openvscode-server %s in %s", pin.Version, name))
+	out, err := spriteExecOutput(ctx, name, nil, "sh", "-lc", ideInstallScript(pin))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven ide failed: openvscode-server install failed: %v%s\n", err, gstackOutputTail(out))
+		os.Exit(1)
+	}
+	if s := strings.TrimSpace(out); s != "" {
+		logger(s)
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven ide failed: generate connection token: %v\n", err)
+		os.Exit(1)
+	}
+	env := []string{
+		"SEVEN_IDE_VERSION=" + pin.Version,
+		"SEVEN_IDE_PORT=" + strconv.Itoa(ideDefaultPort),
+		"SEVEN_IDE_TOKEN=" + token,
+		"SEVEN_REPO_DIR=" + spriteFamilyBase(name),
+	}
+	if out, err := spriteExecOutput(ctx, name, env, "sh", "-lc", ideStartScript); err != nil {
+		fmt.Fprintf(os.Stderr, "seven ide failed: openvscode-server did not start: %v%s\n", err, gstackOutputTail(out))
+		os.Exit(1)
+	}
+
+	url := fmt.Sprintf("http://localhost:%d/?tkn=%s", port, token)
+	logger(fmt.Sprintf("[seven ide] openvscode-server running in ~/%s", spriteFamilyBase(name)))
+	fmt.Printf("\n  %s\n\n", url)
+	if *open {
+		if err := openBrowser(url); err != nil {
+			logger(fmt.Sprintf("[seven ide] could not open a browser: %v", err))
+		}
+	}
+
+	// The token is per session: stop the server on exit so the URL stops
+	// working. Ctrl-C has cancelled ctx by then, so the stop outlives it.
+	runDevProxies(ctx, forwarder, name, []devPort{{Local: port, Remote: ideDefaultPort}}, logger)
+	if out, err := spriteExecOutput(context.WithoutCancel(ctx), name, nil, "sh", "-lc", ideStopScript); err != nil {
+		fmt.Fprintf(os.Stderr, "seven ide: stopping openvscode-server failed: %v%s\n", err, gstackOutputTail(out))
+		os.Exit(1)
+	}
+	logger("[seven ide] stopped openvscode-server")
+}
+
+// randomToken returns 24 random bytes, hex-encoded.
+func randomToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// ideInstallScript installs the pinned openvscode-server release under
+// ~/.local/share/seven, reusing the tooling manifest's verified archive fetch.
+// A release already installed from the same digest is left alone. The archive
+// must unpack into its single expected top-level directory, and the new tree
+// replaces the old one only after it has been fully extracted.
+func ideInstallScript(pin idePin) string {
+	return `set -u
+` + verifiedArchiveFetchFunc + `
+
+ide_version="` + pin.Version + `"
+ide_root="$HOME/.local/share/seven"
+ide_dir="$ide_root/openvscode-server-v$ide_version"
+case "$(uname -m)" in
+  x86_64|amd64) ide_arch="x64"; ide_sha="` + pin.SHA256X86_64 + `" ;;
+  aarch64|arm64) ide_arch="arm64"; ide_sha="` + pin.SHA256Arm64 + `" ;;
+  *) echo "[seven ide] unsupported architecture: $(uname -m)"; exit 1 ;;
+esac
+if [ -x "$ide_dir/bin/openvscode-server" ] && [ "$(cat "$ide_dir/.seven-sha256" 2>/dev/null)" = "$ide_sha" ]; then
+  echo "[seven ide] openvscode-server $ide_version already installed"
+  exit 0
+fi
+ide_top="openvscode-server-v$ide_version-linux-$ide_arch"
+ide_url="https://github.com/gitpod-io/openvscode-server/releases/download/openvscode-server-v$ide_version/$ide_top.tar.gz"
+ide_tmp="$(mktemp -d)" || exit 1
+trap 'rm -rf "$ide_tmp"' EXIT
+if ! fetch_verified_archive "$ide_url" "$ide_sha" "$ide_tmp/archive.tgz"; then
+  echo "[seven ide] download or SHA-256 verification failed: $ide_url"
+  exit 1
+fi
+ide_listing="$(tar -tzf "$ide_tmp/archive.tgz" 2>/dev/null)" || exit 1
+if ! printf '%s\n' "$ide_listing" | while IFS= read -r ide_entry; do
+  case "$ide_entry" in "$ide_top"|"$ide_top"/*) ;; *) exit 1 ;; esac
+  case "/$ide_entry/" in */../*) exit 1 ;; esac
+done; then
+  echo "[seven ide] unexpected archive layout"
+  exit 1
+fi
+mkdir -p "$ide_tmp/staging" "$ide_root" &&
+  tar -xzf "$ide_tmp/archive.tgz" -C "$ide_tmp/staging" --no-same-owner &&
+  [ -x "$ide_tmp/staging/$ide_top/bin/openvscode-server" ] &&
+  printf '%s\n' "$ide_sha" > "$ide_tmp/staging/$ide_top/.seven-sha256" || exit 1
+rm -rf "$ide_dir.old"
+if [ -e "$ide_dir" ]; then mv "$ide_dir" "$ide_dir.old" || exit 1; fi
+if ! mv "$ide_tmp/staging/$ide_top" "$ide_dir"; then
+  if [ -e "$ide_dir.old" ]; then mv "$ide_dir.old" "$ide_dir"; fi
+  exit 1
+fi
+rm -rf "$ide_dir.old"
+echo "[seven ide] installed openvscode-server $ide_version"`
+}
+
+// ideStartScript (re)starts openvscode-server bound to localhost only, in the
+// cloned repo, with this session's connection token. The token is written to
+// a 0600 file rather than passed on the command line, where it would be
+// visible in the process list. It waits until the server answers HTTP.
+const ideStartScript = `set -eu
+ide_dir="$HOME/.local/share/seven/openvscode-server-v$SEVEN_IDE_VERSION"
+ide_repo="$HOME/$SEVEN_REPO_DIR"
+[ -d "$ide_repo" ] || ide_repo="$HOME"
+if [ -f "$HOME/.seven-ide.pid" ]; then
+  kill "$(cat "$HOME/.seven-ide.pid")" 2>/dev/null || true
+  rm -f "$HOME/.seven-ide.pid"
+fi
+umask 077
+printf '%s' "$SEVEN_IDE_TOKEN" > "$HOME/.seven-ide-token"
+cd "$ide_repo"
+setsid nohup "$ide_dir/bin/openvscode-server" --host 127.0.0.1 --port "$SEVEN_IDE_PORT" \
+  --connection-token-file "$HOME/.seven-ide-token" --default-folder "$ide_repo" \
+  </dev/null >"$HOME/.seven-ide.log" 2>&1 &
+ide_pid=$!
+echo "$ide_pid" > "$HOME/.seven-ide.pid"
+i=0
+while [ "$i" -lt 100 ]; do
+  if ! kill -0 "$ide_pid" 2>/dev/null; then
+    tail -n 20 "$HOME/.seven-ide.log" >&2
+    exit 1
+  fi
+  if curl -s -o /dev/null "http://127.0.0.1:$SEVEN_IDE_PORT/"; then
+    exit 0
+  fi
+  sleep 0.2
+  i=$((i + 1))
+done
+echo "openvscode-server did not answer on port $SEVEN_IDE_PORT" >&2
+exit 1`
+
+const ideStopScript = `if [ -f "$HOME/.seven-ide.pid" ]; then
+  kill "$(cat "$HOME/.seven-ide.pid")" 2>/dev/null || true
+fi
+rm -f "$HOME/.seven-ide.pid" "$HOME/.seven-ide-token"`
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testIdeSHA = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// runIdeInstallScript runs ideInstallScript for an archive fixture with the fake
+// curl and uname from writeArchiveTestCommands ahead of the host PATH.
+func runIdeInstallScript(t *testing.T, home, archivePath string, pin idePin) (string, error) {
+	t.Helper()
+	binDir := filepath.Join(t.TempDir(), "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeArchiveTestCommands(t, binDir, archivePath)
+	cmd := exec.Command("sh", "-c", ideInstallScript(pin))
+	cmd.Env = []string{
+		"HOME=" + home,
+		"PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH"),
+	}
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestIdeInstallScript(t *testing.T) {
+	server := []byte("#!/bin/sh\necho openvscode-server\n")
+	release := []tarFixtureEntry{
+		{header: tar.Header{Name: "openvscode-server-v1.2.3-linux-x64/", Mode: 0o755, Typeflag: tar.TypeDir}},
+		{header: tar.Header{Name: "openvscode-server-v1.2.3-linux-x64/bin/", Mode: 0o755, Typeflag: tar.TypeDir}},
+		{header: tar.Header{Name: "openvscode-server-v1.2.3-linux-x64/bin/openvscode-server", Mode: 0o755, Typeflag: tar.TypeReg}, content: server},
+	}
+
+	t.Run("installs the pinned release once", func(t *testing.T) {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, "release.tar.gz")
+		sum := fmt.Sprintf("%x", sha256.Sum256(writeTarFixture(t, archivePath, release)))
+		home := t.TempDir()
+		pin := idePin{Version: "1.2.3", SHA256X86_64: sum, SHA256Arm64: testIdeSHA}
+
+		out, err := runIdeInstallScript(t, home, archivePath, pin)
+		if err != nil || !strings.Contains(out, "installed openvscode-server 1.2.3") {
+			t.Fatalf("expected install, err=%v output=%s", err, out)
+		}
+		bin := filepath.Join(home, ".local", "share", "seven", "openvscode-server-v1.2.3", "bin", "openvscode-server")
+		if got, err := exec.Command(bin).CombinedOutput(); err != nil || !strings.Contains(string(got), "openvscode-server") {
+			t.Fatalf("installed server is not runnable, err=%v output=%s", err, got)
+		}
+
+		out, err = runIdeInstallScript(t, home, archivePath, pin)
+		if err != nil || !strings.Contains(out, "already installed") {
+			t.Fatalf("expected reinstall to be skipped, err=%v output=%s", err, out)
+		}
+	})
+
+	t.Run("rejects a checksum mismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, "release.tar.gz")
+		writeTarFixture(t, archivePath, release)
+		home := t.TempDir()
+
+		out, err := runIdeInstallScript(t, home, archivePath, idePin{Version: "1.2.3", SHA256X86_64: testIdeSHA, SHA256Arm64: testIdeSHA})
+		if err == nil || !strings.Contains(out, "SHA-256 verification failed") {
+			t.Fatalf("expected checksum mismatch to fail closed, err=%v output=%s", err, out)
+		}
+		if _, err := os.Stat(filepath.Join(home, ".local", "share", "seven", "openvscode-server-v1.2.3")); !os.IsNotExist(err) {
+			t.Fatalf("nothing may be installed from an unverified archive, stat err=%v", err)
+		}
+	})
+
+	t.Run("rejects entries outside the release directory", func(t *testing.T) {
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir, "release.tar.gz")
+		entries := append(append([]tarFixtureEntry{}, release...), tarFixtureEntry{
+			header: tar.Header{Name: "openvscode-server-v1.2.3-linux-x64/../escape", Mode: 0o644, Typeflag: tar.TypeReg}, content: []byte("x"),
+		})
+		sum := fmt.Sprintf("%x", sha256.Sum256(writeTarFixture(t, archivePath, entries)))
+
+		out, err := runIdeInstallScript(t, t.TempDir(), archivePath, idePin{Version: "1.2.3", SHA256X86_64: sum, SHA256Arm64: testIdeSHA})
+		if err == nil || !strings.Contains(out, "unexpected archive layout") {
+			t.Fatalf("expected traversal entry to fail closed, err=%v output=%s", err, out)
+		}
+	})
+}
+
+func TestSevenIdeStartsServerAndStopsOnInterrupt(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	config := "[ide]\nversion = \"1.2.3\"\nsha256_x86_64 = \"" + testIdeSHA + "\"\nsha256_arm64 = \"" + testIdeSHA + "\"\nport = 4000\n"
+	if err := os.WriteFile(filepath.Join(repo, ".seven.toml"), []byte(config), 0o644); err != nil {
+		t.Fatalf("failed to write .seven.toml: %v", err)
+	}
+
+	cmd := exec.Command(testSevenBin, "ide")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	output := &strings.Builder{}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start seven ide: %v", err)
+	}
+	log := waitForLog(t, logPath, 1, "proxy -s hello 4000:3939")
+	if !strings.Contains(log, "openvscode-server-v$ide_version") || !strings.Contains(log, "SEVEN_IDE_PORT=3939") {
+		t.Fatalf("expected install and start scripts before forwarding, got: %s", log)
+	}
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatalf("interrupt seven ide: %v", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("seven ide should exit cleanly on interrupt: %v\n%s", err, output)
+	}
+
+	url := regexp.MustCompile(`http://localhost:4000/\?tkn=([0-9a-f]{48})`).FindStringSubmatch(output.String())
+	if url == nil {
+		t.Fatalf("expected tokenized URL in output, got: %s", output)
+	}
+	data, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(data), "SEVEN_IDE_TOKEN="+url[1]) {
+		t.Fatalf("expected the printed token to be the one the server was started with, got: %s", data)
+	}
+	stopped := strings.Index(string(data), `rm -f "$HOME/.seven-ide.pid" "$HOME/.seven-ide-token"`)
+	if stopped < strings.Index(string(data), "proxy -s hello") {
+		t.Fatalf("expected the server to be stopped after forwarding ends, got: %s", data)
+	}
+}
+
+func TestSevenIdeRequiresPinnedRelease(t *testing.T) {
+	repo := t.TempDir()
+	cmd := exec.Command(testSevenBin, "ide")
+	cmd.Dir = repo
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "pin openvscode-server in .seven.toml") {
+		t.Fatalf("expected missing pin to fail, err=%v output=%s", err, out)
+	}
+}
+
+func TestParseProjectConfigIde(t *testing.T) {
+	config, err := parseProjectConfig("[ide]\nversion = \"1.2.3\"\nsha256_x86_64 = \"" + testIdeSHA + "\"\nsha256_arm64 = \"" + testIdeSHA + "\"\n")
+	if err != nil {
+		t.Fatalf("parseProjectConfig: %v", err)
+	}
+	if config.Ide.Pin.Version != "1.2.3" || config.Ide.Port != ideDefaultPort {
+		t.Fatalf("unexpected ide config: %+v", config.Ide)
+	}
+	for _, contents := range []string{
+		"[ide]\nversion = \"latest\"\nsha256_x86_64 = \"" + testIdeSHA + "\"\nsha256_arm64 = \"" + testIdeSHA + "\"\n",
+		"[ide]\nversion = \"1.2.3\"\nsha256_x86_64 = \"" + testIdeSHA + "\"\n",
+	} {
+		if _, err := parseProjectConfig(contents); err == nil {
+			t.Fatalf("expected incomplete or unpinned [ide] to be rejected: %q", contents)
+		}
+	}
+	if _, err := parseProjectConfig("[ide]\nport = 70000\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected out-of-range port to be rejected with its line, got %v", err)
+	}
+}
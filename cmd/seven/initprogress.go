@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// sevenInitProgressPath lists the init steps a sprite has completed, one per
+// line. It exists only while an init is unfinished: runInit writes it right
+// after creating the sprite, rewrites it with every completed step when a
+// later step fails, and removes it once the last step succeeds. A sprite
+// without it is fully initialized.
+const sevenInitProgressPath = "$HOME/.seven-init-progress"
+
+// runInit's resumable steps, in order.
+const (
+	initStepCreated       = "created"
+	initStepIdentity      = "identity"
+	initStepGhAuth        = "gh-auth"
+	initStepAssistantSync = "assistant-sync"
+	initStepCloned        = "cloned"
+	initStepBootstrap     = "bootstrap"
+	initStepTooling       = "tooling"
+)
+
+var initSteps = []string{
+	initStepCreated,
+	initStepIdentity,
+	initStepGhAuth,
+	initStepAssistantSync,
+	initStepCloned,
+	initStepBootstrap,
+	initStepTooling,
+}
+
+type initProgress struct {
+	Unfinished bool     // the sprite has a progress marker
+	Done       []string // completed steps, in order
+}
+
+func (p initProgress) has(step string) bool {
+	return slices.Contains(p.Done, step)
+}
+
+// last names the most recent completed step, or "none".
+func (p initProgress) last() string {
+	if len(p.Done) == 0 {
+		return "none"
+	}
+	return p.Done[len(p.Done)-1]
+}
+
+// run performs step unless an earlier init already completed it. A step
+// cut short by cancelling ctx is not recorded as done, even when it treats
+// its own failures as non-fatal.
+func (p *initProgress) run(ctx context.Context, step string, opts upOptions, fn func() error) error {
+	if p.has(step) {
+		opts.Logger(fmt.Sprintf("[seven init] step already done: %s", step))
+		return nil
+	}
+	began := time.Now()
+	if err := fn(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	opts.Timings.record(step, began, false)
+	p.Done = append(p.Done, step)
+	return nil
+}
+
+// parseInitProgress reads the marker's contents; unknown lines are ignored so
+// a newer seven's marker does not make an older one fail.
+func parseInitProgress(contents string) initProgress {
+	var p initProgress
+	for _, line := range strings.Split(contents, "\n") {
+		step := strings.TrimSpace(line)
+		if slices.Contains(initSteps, step) && !p.has(step) {
+			p.Done = append(p.Done, step)
+		}
+		p.Unfinished = p.Unfinished || step != ""
+	}
+	return p
+}
+
+func readInitProgress(ctx context.Context, spriteName string) (initProgress, error) {
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", `cat "`+sevenInitProgressPath+`" 2>/dev/null || true`)
+	if err != nil {
+		return initProgress{}, fmt.Errorf("read init progress: %w%s", err, gstackOutputTail(out))
+	}
+	return parseInitProgress(out), nil
+}
+
+func writeInitProgress(ctx context.Context, spriteName string, p initProgress) error {
+	args := append([]string{"sh", "-lc", `printf '%s\n' "$@" > "` + sevenInitProgressPath + `"`, "sh"}, p.Done...)
+	out, err := spriteExecOutput(ctx, spriteName, nil, args...)
+	if err != nil {
+		return fmt.Errorf("record init progress: %w%s", err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+func clearInitProgress(ctx context.Context, spriteName string) error {
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", `rm -f "`+sevenInitProgressPath+`"`)
+	if err != nil {
+		return fmt.Errorf("clear init progress: %w%s", err, gstackOutputTail(out))
+	}
+	return nil
+}
+
+// unfinishedInitError stops a plain `seven up` from treating a half-provisioned
+// sprite as ready.
+func unfinishedInitError(spriteName string, p initProgress) error {
+	return fmt.Errorf("sprite %s has an unfinished init (last completed step: %s); run `seven up --resume` to continue or `seven destroy` to start over", spriteName, p.last())
+}
@@ -5,11 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"seven/backend"
 )
 
 func integrationSevenEnv() []string {
@@ -17,16 +19,7 @@ func integrationSevenEnv() []string {
 }
 
 func TestIntegrationUpDestroy(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 
 	repo := t.TempDir()
 	name := uniqueSpriteName()
@@ -62,29 +55,20 @@ func TestIntegrationUpDestroy(t *testing.T) {
 		t.Fatalf(".sprite should contain a name")
 	}
 
-	if !spriteListed(name) {
+	if !spriteListed(sandbox, name) {
 		defer destroySprite(t, repo)
 		t.Fatalf("sprite not found in list: %s", name)
 	}
 
 	destroySprite(t, repo)
 
-	if spriteListed(name) {
+	if spriteListed(sandbox, name) {
 		t.Fatalf("sprite still present after destroy: %s", name)
 	}
 }
 
 func TestIntegrationInitWithGitRemote(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
 	}
@@ -109,54 +93,42 @@ func TestIntegrationInitWithGitRemote(t *testing.T) {
 	}
 
 	// Ensure the repo was cloned inside the sprite.
-	check := exec.Command("sprite", "exec", "-s", name, "test", "-d", name)
-	check.Stdout = os.Stdout
-	check.Stderr = os.Stderr
-	if err := check.Run(); err != nil {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+	if err := sandboxRun(sandbox, name, "test", "-d", name); err != nil {
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected repo directory in sprite: %v", err)
 	}
 
-	hookCheck := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "test -f \"$HOME/.seven-console-hook.sh\"")
-	hookCheck.Stdout = os.Stdout
-	hookCheck.Stderr = os.Stderr
-	if err := hookCheck.Run(); err != nil {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+	if err := sandboxRun(sandbox, name, "sh", "-lc", "test -f \"$HOME/.seven-console-hook.sh\""); err != nil {
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected console hook file in sprite: %v", err)
 	}
 
-	bashRcCheck := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "grep -Fq '[ -f \"$HOME/.seven-console-hook.sh\" ] && . \"$HOME/.seven-console-hook.sh\"' \"$HOME/.bashrc\"")
-	bashRcCheck.Stdout = os.Stdout
-	bashRcCheck.Stderr = os.Stderr
-	if err := bashRcCheck.Run(); err != nil {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+	if err := sandboxRun(sandbox, name, "sh", "-lc", "grep -Fq '[ -f \"$HOME/.seven-console-hook.sh\" ] && . \"$HOME/.seven-console-hook.sh\"' \"$HOME/.bashrc\""); err != nil {
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected console hook source line in .bashrc: %v", err)
 	}
 
-	zshRcCheck := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "grep -Fq '[ -f \"$HOME/.seven-console-hook.sh\" ] && . \"$HOME/.seven-console-hook.sh\"' \"$HOME/.zshrc\"")
-	zshRcCheck.Stdout = os.Stdout
-	zshRcCheck.Stderr = os.Stderr
-	if err := zshRcCheck.Run(); err != nil {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+	if err := sandboxRun(sandbox, name, "sh", "-lc", "grep -Fq '[ -f \"$HOME/.seven-console-hook.sh\" ] && . \"$HOME/.seven-console-hook.sh\"' \"$HOME/.zshrc\""); err != nil {
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected console hook source line in .zshrc: %v", err)
 	}
 
-	markerOut, err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "cat \"$HOME/.seven-console-once\"").CombinedOutput()
+	markerOut, err := sandboxOutput(sandbox, name, "sh", "-lc", "cat \"$HOME/.seven-console-once\"")
 	if err != nil {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected one-shot marker file in sprite: %v\n%s", err, markerOut)
 	}
 	markerLines := strings.Split(strings.TrimSpace(string(markerOut)), "\n")
 	if len(markerLines) < 2 {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected marker to have repo path and assistant, got: %q", markerOut)
 	}
 	if !strings.HasSuffix(markerLines[0], "/"+name) {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected marker repo path to end with /%s, got: %q", name, markerLines[0])
 	}
 	if markerLines[1] != "codex" {
-		_ = exec.Command("sprite", "destroy", "--force", name).Run()
+		_ = sandbox.Destroy(context.Background(), name)
 		t.Fatalf("expected marker assistant to be codex, got: %q", markerLines[1])
 	}
 
@@ -164,16 +136,7 @@ func TestIntegrationInitWithGitRemote(t *testing.T) {
 }
 
 func TestIntegrationConsoleBootstrapRunsCodexInRepo(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
 	}
@@ -199,7 +162,7 @@ func TestIntegrationConsoleBootstrapRunsCodexInRepo(t *testing.T) {
 
 	defer destroySprite(t, repo)
 
-	setupStub := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", `set -e
+	if err := sandboxRun(sandbox, name, "sh", "-lc", `set -e
 install -d "$HOME/.seven-test-bin"
 cat > "$HOME/.seven-test-bin/codex" <<'EOF'
 #!/bin/sh
@@ -216,17 +179,14 @@ for rc in "$HOME/.bash_profile" "$HOME/.profile" "$HOME/.bashrc" "$HOME/.zshrc"
   } > "$tmp"
   mv "$tmp" "$rc"
 done
-`)
-	setupStub.Stdout = os.Stdout
-	setupStub.Stderr = os.Stderr
-	if err := setupStub.Run(); err != nil {
+`); err != nil {
 		t.Fatalf("failed to set up codex stub in sprite: %v", err)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
 
-	console := exec.CommandContext(ctx, "sprite", "console", "-s", name)
+	console := integrationConsoleCommand(ctx, sandbox, name)
 	console.Stdout = os.Stdout
 	console.Stderr = os.Stderr
 	console.Env = append(os.Environ(), "SHELL=/bin/bash")
@@ -237,7 +197,7 @@ done
 		t.Fatalf("sprite console failed: %v", err)
 	}
 
-	cwdOut, err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "cat \"$HOME/.seven-codex-cwd\"").CombinedOutput()
+	cwdOut, err := sandboxOutput(sandbox, name, "sh", "-lc", "cat \"$HOME/.seven-codex-cwd\"")
 	if err != nil {
 		t.Fatalf("expected codex stub to write cwd file: %v\n%s", err, cwdOut)
 	}
@@ -246,25 +206,13 @@ done
 		t.Fatalf("expected codex to run from repo dir /%s, got: %q", name, cwd)
 	}
 
-	markerCheck := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "test ! -f \"$HOME/.seven-console-once\"")
-	markerCheck.Stdout = os.Stdout
-	markerCheck.Stderr = os.Stderr
-	if err := markerCheck.Run(); err != nil {
+	if err := sandboxRun(sandbox, name, "sh", "-lc", "test ! -f \"$HOME/.seven-console-once\""); err != nil {
 		t.Fatalf("expected one-shot marker to be consumed after first console: %v", err)
 	}
 }
 
 func TestIntegrationInitNormalizesForbiddenDirName(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 
 	parent := t.TempDir()
 	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
@@ -296,22 +244,13 @@ func TestIntegrationInitNormalizesForbiddenDirName(t *testing.T) {
 
 	defer destroySprite(t, repo)
 
-	if !spriteListed(name) {
+	if !spriteListed(sandbox, name) {
 		t.Fatalf("sprite not found in list: %s", name)
 	}
 }
 
 func TestIntegrationGhAuthPersistsInSprite(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 	if _, err := exec.LookPath("gh"); err != nil {
 		t.Skip("gh CLI not found in PATH")
 	}
@@ -350,29 +289,17 @@ func TestIntegrationGhAuthPersistsInSprite(t *testing.T) {
 
 	defer destroySprite(t, repo)
 
-	if err := exec.Command("sprite", "exec", "-s", name, "gh", "--version").Run(); err != nil {
+	if _, err := sandboxOutput(sandbox, name, "gh", "--version"); err != nil {
 		t.Skip("gh not available in sprite")
 	}
 
-	auth := exec.Command("sprite", "exec", "-s", name, "gh", "auth", "status", "-h", "github.com")
-	auth.Stdout = os.Stdout
-	auth.Stderr = os.Stderr
-	if err := auth.Run(); err != nil {
+	if err := sandboxRun(sandbox, name, "gh", "auth", "status", "-h", "github.com"); err != nil {
 		t.Fatalf("expected gh auth status to succeed in sprite: %v", err)
 	}
 }
 
 func TestIntegrationCodexChatGPTAuthPersistsInSprite(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 	if _, err := exec.LookPath("codex"); err != nil {
 		t.Skip("codex CLI not found in PATH")
 	}
@@ -407,12 +334,11 @@ func TestIntegrationCodexChatGPTAuthPersistsInSprite(t *testing.T) {
 
 	defer destroySprite(t, repo)
 
-	if err := exec.Command("sprite", "exec", "-s", name, "codex", "--version").Run(); err != nil {
+	if _, err := sandboxOutput(sandbox, name, "codex", "--version"); err != nil {
 		t.Skip("codex not available in sprite")
 	}
 
-	status := exec.Command("sprite", "exec", "-s", name, "codex", "login", "status")
-	out, err := status.CombinedOutput()
+	out, err := sandboxOutput(sandbox, name, "codex", "login", "status")
 	if err != nil {
 		t.Fatalf("expected codex login status to succeed in sprite: %v\n%s", err, out)
 	}
@@ -426,16 +352,7 @@ func TestIntegrationCodexChatGPTAuthPersistsInSprite(t *testing.T) {
 // script (projectToolingInstallScript) over a manifest declaring a small pinned npm tool, then
 // asserts the tool is actually installed and on PATH, and that a second run is a no-op (idempotent).
 func TestIntegrationProjectToolingInstallsFromManifest(t *testing.T) {
-	if os.Getenv("SEVEN_INTEGRATION") != "1" {
-		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
-	}
-
-	if _, err := exec.LookPath("sprite"); err != nil {
-		t.Skip("sprite CLI not found in PATH")
-	}
-	if err := exec.Command("sprite", "list").Run(); err != nil {
-		t.Skip("sprite list failed; ensure you are logged in")
-	}
+	sandbox := integrationBackend(t)
 
 	repo := t.TempDir()
 	name := uniqueSpriteName()
@@ -454,7 +371,7 @@ func TestIntegrationProjectToolingInstallsFromManifest(t *testing.T) {
 	defer destroySprite(t, repo)
 
 	// npm is a prerequisite for the install path; without it the script is a documented no-op.
-	if err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "command -v npm").Run(); err != nil {
+	if _, err := sandboxOutput(sandbox, name, "sh", "-lc", "command -v npm"); err != nil {
 		t.Skip("npm not available in sprite")
 	}
 
@@ -462,18 +379,17 @@ func TestIntegrationProjectToolingInstallsFromManifest(t *testing.T) {
 	// `command -v semver` fails before install and passes after — independent of the tool's flags.
 	manifestPath := "$HOME/tooling-it.manifest"
 	manifestBody := "npm semver semver@7.5.4 command -v semver\n"
-	writeManifest := exec.Command("sprite", "exec", "-s", name, "sh", "-lc",
-		"cat > \""+manifestPath+"\" <<'MANIFEST_EOF'\n"+manifestBody+"MANIFEST_EOF")
-	if out, err := writeManifest.CombinedOutput(); err != nil {
+	if out, err := sandboxOutput(sandbox, name, "sh", "-lc",
+		"cat > \""+manifestPath+"\" <<'MANIFEST_EOF'\n"+manifestBody+"MANIFEST_EOF"); err != nil {
 		t.Fatalf("failed to write manifest in sprite: %v\n%s", err, out)
 	}
 
 	// Start from a clean slate so the first run genuinely installs (not "already present").
-	_ = exec.Command("sprite", "exec", "-s", name, "sh", "-lc", "npm rm -g semver >/dev/null 2>&1 || true").Run()
+	_, _ = sandboxOutput(sandbox, name, "sh", "-lc", "npm rm -g semver >/dev/null 2>&1 || true")
 
 	script := projectToolingInstallScript(manifestPath)
 
-	firstOut, err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", script).CombinedOutput()
+	firstOut, err := sandboxOutput(sandbox, name, "sh", "-lc", script)
 	if err != nil {
 		t.Fatalf("first tooling install failed: %v\n%s", err, firstOut)
 	}
@@ -484,14 +400,14 @@ func TestIntegrationProjectToolingInstallsFromManifest(t *testing.T) {
 	// The tool must really be installed — assert its bin exists in npm's global bin dir (npm's own
 	// prefix, independent of interactive-shell PATH), proving install happened and is not just an
 	// `npm i -g` that exited 0.
-	if err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", `test -f "$(npm prefix -g)/bin/semver"`).Run(); err != nil {
+	if _, err := sandboxOutput(sandbox, name, "sh", "-lc", `test -f "$(npm prefix -g)/bin/semver"`); err != nil {
 		t.Fatalf("expected semver bin in npm global bin dir after install: %v", err)
 	}
 
 	// Second run: the script puts npm's global bin on PATH so the verify-command resolves the
 	// just-installed tool and the row is skipped — the idempotent, fast no-op `seven up` promises.
 	// (This is exactly what fails if the install script does not augment PATH before verifying.)
-	secondOut, err := exec.Command("sprite", "exec", "-s", name, "sh", "-lc", script).CombinedOutput()
+	secondOut, err := sandboxOutput(sandbox, name, "sh", "-lc", script)
 	if err != nil {
 		t.Fatalf("second tooling install failed: %v\n%s", err, secondOut)
 	}
@@ -500,13 +416,49 @@ func TestIntegrationProjectToolingInstallsFromManifest(t *testing.T) {
 	}
 }
 
-func spriteListed(name string) bool {
-	out, err := exec.Command("sprite", "list").CombinedOutput()
+// integrationBackend skips unless SEVEN_INTEGRATION=1 and the backend named
+// by SEVEN_BACKEND (the sprite CLI by default) is usable. The seven binary
+// under test inherits SEVEN_BACKEND through integrationSevenEnv, so
+// SEVEN_BACKEND=docker runs the whole suite without a Sprite login.
+func integrationBackend(t *testing.T) backend.Backend {
+	t.Helper()
+	if os.Getenv("SEVEN_INTEGRATION") != "1" {
+		t.Skip("set SEVEN_INTEGRATION=1 to run integration tests")
+	}
+	sandbox, err := newBackend(userSettings{Backend: os.Getenv("SEVEN_BACKEND")})
 	if err != nil {
-		return false
+		t.Fatal(err)
+	}
+	if _, err := exec.LookPath(sandbox.Name()); err != nil {
+		t.Skipf("%s CLI not found in PATH", sandbox.Name())
+	}
+	if _, err := sandbox.List(context.Background()); err != nil {
+		t.Skipf("%s list failed; ensure you are logged in or the daemon is running: %v", sandbox.Name(), err)
+	}
+	return sandbox
+}
+
+func sandboxRun(sandbox backend.Backend, name string, args ...string) error {
+	return sandbox.Exec(context.Background(), name, backend.ExecRequest{Command: args, Stdout: os.Stdout, Stderr: os.Stderr})
+}
+
+func sandboxOutput(sandbox backend.Backend, name string, args ...string) ([]byte, error) {
+	out, err := sandbox.ExecOutput(context.Background(), name, backend.ExecRequest{Command: args})
+	return []byte(out), err
+}
+
+// integrationConsoleCommand opens the backend's console without a terminal,
+// so the shell reads EOF and exits once the bootstrap hook has run.
+func integrationConsoleCommand(ctx context.Context, sandbox backend.Backend, name string) *exec.Cmd {
+	if c, ok := sandbox.(containerBackend); ok {
+		return exec.CommandContext(ctx, c.runtime, "exec", "--interactive", containerName(name), "bash", "-il")
 	}
-	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
-	return re.Match(out)
+	return exec.CommandContext(ctx, "sprite", "console", "-s", name)
+}
+
+func spriteListed(sandbox backend.Backend, name string) bool {
+	names, err := sandbox.List(context.Background())
+	return err == nil && slices.Contains(names, name)
 }
 
 func destroySprite(t *testing.T, repo string) {
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// jsonSchemaVersion is reported in every --json document. Bump it only for
+// incompatible changes (renamed or removed fields, changed meanings); adding a
+// field is compatible and keeps the version.
+const jsonSchemaVersion = 1
+
+// spriteJSON describes one sprite of the repo's family.
+type spriteJSON struct {
+	Name     string `json:"name"`
+	Ordinal  int    `json:"ordinal"` // 1 = main sprite, N = sibling "<base>-0N"
+	Main     bool   `json:"main"`
+	Selected bool   `json:"selected"`
+	Exists   bool   `json:"exists"`
+	Color    string `json:"color"` // ANSI 256-color code of the sprite's prompt
+}
+
+// listJSON is the `seven list --json` document.
+type listJSON struct {
+	SchemaVersion int          `json:"schema_version"`
+	Family        string       `json:"family"`
+	Selected      string       `json:"selected"`
+	Origin        string       `json:"origin"` // ".sprite", ".seven.toml", or "cwd"
+	Sprites       []spriteJSON `json:"sprites"`
+}
+
+// statusJSON is the `seven status --json` document.
+type statusJSON struct {
+	SchemaVersion int        `json:"schema_version"`
+	Origin        string     `json:"origin"`
+	Sprite        spriteJSON `json:"sprite"`
+}
+
+// upJSON is the `seven up --no-console --json` document.
+type upJSON struct {
+	SchemaVersion int        `json:"schema_version"`
+	Origin        string     `json:"origin"` // how the sprite name was selected, as in list/status
+	Sprite        spriteJSON `json:"sprite"`
+	Created       bool       `json:"created"` // false when an existing sprite was reused
+	OpenConsole   bool       `json:"open_console"`
+}
+
+func newSpriteJSON(base, name string, selected, exists bool) spriteJSON {
+	ordinal, _ := spriteFamilyOrdinal(base, name)
+	return spriteJSON{
+		Name:     name,
+		Ordinal:  ordinal,
+		Main:     name == base,
+		Selected: selected,
+		Exists:   exists,
+		Color:    spriteColor(name),
+	}
+}
+
+func selectionOrigin(info spriteNameInfo) string {
+	switch {
+	case info.FromFile:
+		return ".sprite"
+	case info.FromConfig:
+		return projectConfigFileName
+	default:
+		return "cwd"
+	}
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runSevenJSON runs seven with args against a fake sprite and decodes stdout,
+// which must hold exactly one JSON document, into v.
+func runSevenJSON(t *testing.T, repo, state, logPath string, v any, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, args...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"HOME="+t.TempDir(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("seven %s failed: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	dec := json.NewDecoder(strings.NewReader(string(stdout)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		t.Fatalf("seven %s: stdout is not the expected JSON: %v\n%s", strings.Join(args, " "), err, stdout)
+	}
+	if dec.More() {
+		t.Fatalf("seven %s: expected a single JSON document, got:\n%s", strings.Join(args, " "), stdout)
+	}
+	return stderr.String()
+}
+
+func TestSevenListJSON(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("seven-02\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("seven\nseven-02\nother-app\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+
+	var doc listJSON
+	runSevenJSON(t, repo, state, logPath, &doc, "list", "--json")
+	if doc.SchemaVersion != jsonSchemaVersion || doc.Family != "seven" || doc.Selected != "seven-02" || doc.Origin != ".sprite" {
+		t.Fatalf("unexpected list document: %+v", doc)
+	}
+	want := []spriteJSON{
+		{Name: "seven", Ordinal: 1, Main: true, Exists: true, Color: spriteColor("seven")},
+		{Name: "seven-02", Ordinal: 2, Selected: true, Exists: true, Color: spriteColor("seven-02")},
+	}
+	if len(doc.Sprites) != len(want) || doc.Sprites[0] != want[0] || doc.Sprites[1] != want[1] {
+		t.Fatalf("unexpected sprites: %+v", doc.Sprites)
+	}
+}
+
+func TestSevenStatusJSON(t *testing.T) {
+	repo := t.TempDir()
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("status-sprite-03\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+
+	var doc statusJSON
+	runSevenJSON(t, repo, state, logPath, &doc, "status", "--json")
+	want := spriteJSON{Name: "status-sprite-03", Ordinal: 3, Selected: true, Color: spriteColor("status-sprite-03")}
+	if doc.SchemaVersion != jsonSchemaVersion || doc.Origin != ".sprite" || doc.Sprite != want {
+		t.Fatalf("unexpected status document: %+v", doc)
+	}
+}
+
+func TestSevenUpJSON(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+
+	var doc upJSON
+	stderr := runSevenJSON(t, repo, state, logPath, &doc, "up", "--assume-logged-in", "--no-console", "--json")
+	if doc.SchemaVersion != jsonSchemaVersion || doc.Origin != ".sprite" || !doc.Created || doc.OpenConsole {
+		t.Fatalf("unexpected up document: %+v", doc)
+	}
+	if doc.Sprite.Name != "hello" || doc.Sprite.Ordinal != 1 || !doc.Sprite.Main || !doc.Sprite.Exists || !doc.Sprite.Selected {
+		t.Fatalf("unexpected up sprite: %+v", doc.Sprite)
+	}
+	if !strings.Contains(stderr, "[seven up] using sprite name: hello") {
+		t.Fatalf("expected progress on stderr, got: %s", stderr)
+	}
+
+	runSevenJSON(t, repo, state, logPath, &doc, "up", "--assume-logged-in", "--no-console", "--json")
+	if doc.Created {
+		t.Fatalf("expected the second up to reuse the sprite: %+v", doc)
+	}
+}
+
+func TestSevenUpJSONReportsCwdOrigin(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	var doc upJSON
+	runSevenJSON(t, repo, state, logPath, &doc, "up", "--assume-logged-in", "--no-console", "--json")
+	if doc.Origin != "cwd" {
+		t.Fatalf("expected a cwd-derived selection, got origin %q", doc.Origin)
+	}
+	if _, err := os.Stat(filepath.Join(repo, ".sprite")); err != nil {
+		t.Fatalf("expected up to write .sprite: %v", err)
+	}
+}
+
+func TestSevenUpJSONRequiresNoConsole(t *testing.T) {
+	cmd := exec.Command(testSevenBin, "up", "--json")
+	cmd.Dir = t.TempDir()
+	out, err := cmd.CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "--json requires --no-console") {
+		t.Fatalf("expected --json without --no-console to fail, err=%v output=%s", err, out)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -9,16 +10,23 @@ import (
 	"hash/fnv"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"seven/backend"
 )
 
 type upResult struct {
@@ -39,11 +47,30 @@ type upOptions struct {
 	InstallGstack  bool
 	FromHost       bool
 	SiblingOrdinal int
+	AutoCheckpoint bool
+	CheckpointKeep int
+	// SkipCredentialSync names assistants (see syncableAssistants) whose host
+	// credentials the user profile keeps out of the sprite.
+	SkipCredentialSync []string
+	SkipSpriteUpgrade  bool
+	// ClaudeOwnLogin keeps the host's Claude credentials out of the sprite,
+	// which logs in on its own instead (see ensureSpriteClaudeLogin).
+	ClaudeOwnLogin bool
+	// RequiredMCPServers are the MCP servers the repo needs live in the
+	// sprite (see mcpConfig).
+	RequiredMCPServers []string
+	// Resume continues an init that failed part-way (see initSteps);
+	// DestroyOnFailure instead destroys the sprite when init fails.
+	Resume           bool
+	DestroyOnFailure bool
+	// Timings, when set, records how long each init step took (--timings).
+	Timings *stepTimings
 }
 
 type spriteNameInfo struct {
 	Name       string
 	FromFile   bool
+	FromConfig bool
 	Original   string
 	Normalized bool
 }
@@ -55,6 +82,19 @@ type hostAssistantState struct {
 	ClaudeConfigPath   string
 	ClaudeAuthPath     string
 	ClaudeCredentials  claudeCredentialsSource
+	CursorConfigPath   string
+	CursorAuthPath     string
+	GeminiConfigPath   string
+	GeminiAuthPath     string
+}
+
+// assistantCommands maps each supported assistant to the command that starts
+// it in the sprite, which the console hint names.
+var assistantCommands = map[string]string{
+	"claude": "claude",
+	"codex":  "codex",
+	"cursor": "cursor-agent",
+	"gemini": "gemini",
 }
 
 // claudeCredentialsSource describes where the host's Claude Code OAuth
@@ -74,6 +114,7 @@ func (c claudeCredentialsSource) present() bool {
 var claudeKeychainServices = []string{"Claude Code-credentials", "claude-code"}
 
 var spritePath string
+var spriteOrg string
 var spriteNamePattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?$`)
 var githubSlugPartPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
 var spriteLatestVersionPattern = regexp.MustCompile(`(?im)^Latest(?:\s+client)?\s+version:\s*(\S+)\s*$`)
@@ -137,20 +178,43 @@ func main() {
 		os.Exit(1)
 	}
 
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "--version", "-v", "version", "help", "-h", "--help":
+	default:
+		args = selectUserProfile(os.Args[1], args)
+	}
+
 	switch os.Args[1] {
 	case "--version", "-v", "version":
 		printVersion()
 		return
 	case "init":
-		cmdInit(os.Args[2:])
+		cmdInit(args)
 	case "up":
-		cmdUp(os.Args[2:])
+		cmdUp(args)
 	case "destroy":
-		cmdDestroy(os.Args[2:])
+		cmdDestroy(args)
 	case "status":
-		cmdStatus(os.Args[2:])
+		cmdStatus(args)
 	case "list", "ls":
-		cmdList(os.Args[2:])
+		cmdList(args)
+	case "checkpoint":
+		cmdCheckpoint(args)
+	case "dev":
+		cmdDev(args)
+	case "ide":
+		cmdIde(args)
+	case "exec":
+		cmdExec(args)
+	case "pull":
+		cmdPull(args)
+	case "push":
+		cmdPush(args)
+	case "sync-auth":
+		cmdSyncAuth(args)
+	case "config":
+		cmdConfig(args)
 	case "help", "-h", "--help":
 		usage()
 	default:
@@ -165,11 +229,21 @@ func usage() {
 	fmt.Printf("version: %s\n", version)
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  seven init [--assume-logged-in] [--new] [--sprite name] [--assistant codex|claude] [--gstack] [--from-host]")
-	fmt.Println("  seven up [N] [--assume-logged-in] [--new] [--sprite name] [--assistant codex|claude] [--no-console] [--no-tui] [--gstack] [--from-host]")
+	fmt.Println("  seven init [--assume-logged-in] [--new] [--sprite name] [--assistant codex|claude|gemini|cursor] [--gstack] [--from-host] [--resume] [--destroy-on-failure] [--timings]")
+	fmt.Println("  seven up [N] [--assume-logged-in] [--new] [--sprite name] [--assistant codex|claude|gemini|cursor] [--no-console] [--no-tui] [--gstack] [--from-host] [--checkpoint] [--checkpoint-keep N] [--json] [--resume] [--destroy-on-failure] [--timings] [--claude-login]")
 	fmt.Println("  seven destroy [name] [--sprite name]")
-	fmt.Println("  seven status")
-	fmt.Println("  seven list")
+	fmt.Println("  seven status [--json]")
+	fmt.Println("  seven list [--json]")
+	fmt.Println("  seven checkpoint create|list|restore|delete [N] ...")
+	fmt.Println("  seven dev [--sprite name] [port|local:remote ...]")
+	fmt.Println("  seven ide [--sprite name] [--port N] [--open]")
+	fmt.Println("  seven exec [N] [--sprite name] [--env KEY=VAL ...] -- cmd [args...]")
+	fmt.Println("  seven pull [N] [--sprite name] [--branch name]")
+	fmt.Println("  seven push [N] [--sprite name] [--branch name] [--force]")
+	fmt.Println("  seven sync-auth [N|--all] [--sprite name]")
+	fmt.Println("  seven config show")
+	fmt.Println()
+	fmt.Println("Every command accepts --profile name (or SEVEN_PROFILE) to pick a profile from ~/.config/seven/config.toml.")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  version  Show version")
@@ -178,6 +252,14 @@ func usage() {
 	fmt.Println("  destroy  Destroy the selected sprite, or a specific sprite by name (positional or --sprite)")
 	fmt.Println("  status   Show sprite status for this repo")
 	fmt.Println("  list     List this repo's sprite family and which one is selected (alias: ls)")
+	fmt.Println("  checkpoint  Create, list, restore, or delete disk snapshots of the selected sprite (or sibling #N)")
+	fmt.Println("  dev      Forward sprite ports to localhost (defaults to [dev] ports in .seven.toml) until Ctrl-C")
+	fmt.Println("  ide      Run a pinned openvscode-server in the sprite and forward it to localhost until Ctrl-C")
+	fmt.Println("  exec     Run a command in the cloned repo of the selected sprite (or sibling #N); exits with its status")
+	fmt.Println("  pull     Fetch the sprite repo's branches into refs/remotes/sprite-<name>/* without going through GitHub")
+	fmt.Println("  push     Seed a sprite branch with unpushed commits and uncommitted changes; verifies the tree matches the host")
+	fmt.Println("  sync-auth  Copy the host's current assistant credentials and gh token into the selected sprite (or #N, or --all siblings)")
+	fmt.Println("  config   Show the effective user config for the selected profile")
 }
 
 var version = "dev"
@@ -186,13 +268,19 @@ func printVersion() {
 	fmt.Println(version)
 }
 
+// normalizeAssistant accepts an assistant name, or the name of its command
+// (e.g. "cursor-agent"), and returns the assistant name.
 func normalizeAssistant(value string) (string, error) {
 	value = strings.ToLower(strings.TrimSpace(value))
 	switch value {
-	case "", "codex", "claude":
+	case "", "codex", "claude", "gemini", "cursor":
 		return value, nil
+	case "gemini-cli":
+		return "gemini", nil
+	case "cursor-agent":
+		return "cursor", nil
 	default:
-		return "", fmt.Errorf("unsupported assistant %q (use codex or claude)", value)
+		return "", fmt.Errorf("unsupported assistant %q (use codex, claude, gemini, or cursor)", value)
 	}
 }
 
@@ -203,25 +291,27 @@ func cmdUp(args []string) {
 	noConsole := fs.Bool("no-console", false, "do not open sprite console after up")
 	newSprite := fs.Bool("new", false, "create and select a new sibling sprite")
 	spriteName := fs.String("sprite", "", "use a specific sprite name")
-	assistant := fs.String("assistant", "", "preferred assistant: codex or claude")
-	gstack := fs.Bool("gstack", false, "install gstack (github.com/garrytan/gstack) into the sprite")
-	fromHost := fs.Bool("from-host", false, "clone the current pushed host branch and require its exact HEAD")
-
-	// An optional leading number (e.g. "seven up 2") selects sibling #N. It must
-	// come first so it is never confused with a flag value like "--sprite 2".
-	ordinal := 0
-	if len(args) > 0 {
-		if n, err := strconv.Atoi(args[0]); err == nil {
-			if n < 1 {
-				fmt.Fprintf(os.Stderr, "seven up failed: sprite number must be a positive integer, got %q\n", args[0])
-				os.Exit(1)
-			}
-			ordinal = n
-			args = args[1:]
-		}
-	}
-
+	assistant := fs.String("assistant", "", "preferred assistant: codex, claude, gemini, or cursor (default from .seven.toml)")
+	gstack := fs.Bool("gstack", false, "install gstack (github.com/garrytan/gstack) into the sprite (default from .seven.toml)")
+	fromHost := fs.Bool("from-host", false, "clone the current pushed host branch and require its exact HEAD (default from .seven.toml)")
+	checkpoint := fs.Bool("checkpoint", false, "checkpoint an existing sprite before opening it (default from .seven.toml)")
+	checkpointKeep := fs.Int("checkpoint-keep", 0, "number of automatic checkpoints to keep (default from .seven.toml, else 5)")
+	asJSON := fs.Bool("json", false, "print the result as machine-readable JSON (requires --no-console; logs go to stderr)")
+	resume := fs.Bool("resume", false, "continue an init that failed part-way instead of refusing the unfinished sprite")
+	destroyOnFailure := fs.Bool("destroy-on-failure", false, "destroy the sprite if init fails instead of keeping it for --resume (for CI)")
+	timings := fs.Bool("timings", false, "report how long each init step took and the wall-clock time saved by running independent steps concurrently")
+	claudeLogin := fs.Bool("claude-login", false, "log Claude Code in inside the sprite with its own device-code login instead of sharing the host's refresh token")
+
+	ordinal, args := parseSpriteOrdinal("up", args)
 	_ = fs.Parse(args)
+	if *asJSON && !*noConsole {
+		fmt.Fprintln(os.Stderr, "seven up failed: --json requires --no-console")
+		os.Exit(1)
+	}
+	if *asJSON && *claudeLogin {
+		fmt.Fprintln(os.Stderr, "seven up failed: --claude-login needs a terminal and cannot be combined with --json")
+		os.Exit(1)
+	}
 	if *newSprite && strings.TrimSpace(*spriteName) != "" {
 		fmt.Fprintln(os.Stderr, "seven up failed: --new and --sprite cannot be used together")
 		os.Exit(1)
@@ -235,27 +325,101 @@ func cmdUp(args []string) {
 		fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
 		os.Exit(1)
 	}
+	projectCfg, err := loadProjectConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
+		os.Exit(1)
+	}
+	// Flags given explicitly on the command line override .seven.toml, which
+	// overrides the user profile.
+	projectCfg.Up.override(fs, preferredAssistant, *gstack, *fromHost)
+	if projectCfg.Up.Assistant == "" {
+		projectCfg.Up.Assistant = activeUserConfig.Settings.Assistant
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "checkpoint":
+			projectCfg.Checkpoint.Auto = *checkpoint
+		case "checkpoint-keep":
+			projectCfg.Checkpoint.Keep = *checkpointKeep
+		}
+	})
+	if projectCfg.Checkpoint.Keep < 1 {
+		fmt.Fprintln(os.Stderr, "seven up failed: --checkpoint-keep must be a positive integer")
+		os.Exit(1)
+	}
 
-	shouldUseTUI := !*noTUI
+	shouldUseTUI := !*noTUI && !*asJSON
 	styleEnabled = shouldUseTUI
 	opts := upOptions{
 		Logger:         func(msg string) { fmt.Println(msg) },
 		QuietExternal:  false,
 		AssumeLoggedIn: *assumeLoggedIn,
 		OpenConsole:    !*noConsole,
-		Assistant:      preferredAssistant,
+		Assistant:      projectCfg.Up.Assistant,
 		SpriteName:     strings.TrimSpace(*spriteName),
 		NewSprite:      *newSprite,
-		InstallGstack:  *gstack,
-		FromHost:       *fromHost,
+		InstallGstack:  projectCfg.Up.Gstack,
+		FromHost:       projectCfg.Up.FromHost,
 		SiblingOrdinal: ordinal,
+		AutoCheckpoint: projectCfg.Checkpoint.Auto,
+		CheckpointKeep: projectCfg.Checkpoint.Keep,
+
+		Resume:           *resume,
+		DestroyOnFailure: *destroyOnFailure,
+
+		SkipCredentialSync: activeUserConfig.Settings.skippedSync(),
+		SkipSpriteUpgrade:  !activeUserConfig.Settings.UpgradeSpriteCLI,
+		ClaudeOwnLogin:     *claudeLogin || activeUserConfig.Settings.ClaudeLogin == "sprite",
+		RequiredMCPServers: projectCfg.MCP.Required,
+	}
+	if *timings {
+		opts.Timings = newStepTimings()
+	}
+	// Ctrl-C stops provisioning and runs init's failure cleanup; stop hands
+	// Ctrl-C back before the console opens.
+	ctx, stop := interruptContext()
+	defer stop()
+	if *asJSON {
+		// Keep stdout a single JSON document: progress goes to stderr and
+		// external command output is captured.
+		opts.Logger = func(msg string) { fmt.Fprintln(os.Stderr, msg) }
+		opts.QuietExternal = true
+		// Resolve the origin before runUp: it writes .sprite, which would
+		// make every run look like a .sprite selection.
+		info, _ := resolveSpriteName()
+		res, err := runUp(ctx, opts)
+		opts.Timings.write(os.Stderr, "seven up")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.ClaudeOwnLogin {
+			opts.Logger("[seven up] not logging claude in: --json has no terminal (run 'seven up --claude-login')")
+		}
+		if err := writeJSON(upJSON{
+			SchemaVersion: jsonSchemaVersion,
+			Origin:        selectionOrigin(info),
+			Sprite:        newSpriteJSON(spriteFamilyBase(res.Name), res.Name, true, true),
+			Created:       !res.SpriteExists,
+			OpenConsole:   res.OpenConsole,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 	if shouldUseTUI {
-		res, err := runUpWithTUI(opts)
+		res, err := runUpWithTUI(ctx, opts)
+		opts.Timings.write(os.Stdout, "seven up")
+		if err == nil && opts.ClaudeOwnLogin {
+			err = ensureSpriteClaudeLogin(ctx, res.Name, "[seven up]", *claudeLogin, opts)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
 			os.Exit(1)
 		}
+		stop()
 		if res.OpenConsole {
 			if err := runConsole(res.Name); err != nil {
 				fmt.Fprintf(os.Stderr, "failed to open console: %v\n", err)
@@ -265,11 +429,16 @@ func cmdUp(args []string) {
 		return
 	}
 
-	res, err := runUp(opts)
+	res, err := runUp(ctx, opts)
+	opts.Timings.write(os.Stdout, "seven up")
+	if err == nil && opts.ClaudeOwnLogin {
+		err = ensureSpriteClaudeLogin(ctx, res.Name, "[seven up]", *claudeLogin, opts)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "seven up failed: %v\n", err)
 		os.Exit(1)
 	}
+	stop()
 	if res.OpenConsole {
 		if err := runConsole(res.Name); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to open console: %v\n", err)
@@ -283,9 +452,12 @@ func cmdInit(args []string) {
 	assumeLoggedIn := fs.Bool("assume-logged-in", false, "skip sprite login")
 	newSprite := fs.Bool("new", false, "create and select a new sibling sprite")
 	spriteName := fs.String("sprite", "", "use a specific sprite name")
-	assistant := fs.String("assistant", "", "preferred assistant: codex or claude")
-	gstack := fs.Bool("gstack", false, "install gstack (github.com/garrytan/gstack) into the sprite")
-	fromHost := fs.Bool("from-host", false, "clone the current pushed host branch and require its exact HEAD")
+	assistant := fs.String("assistant", "", "preferred assistant: codex, claude, gemini, or cursor (default from .seven.toml)")
+	gstack := fs.Bool("gstack", false, "install gstack (github.com/garrytan/gstack) into the sprite (default from .seven.toml)")
+	fromHost := fs.Bool("from-host", false, "clone the current pushed host branch and require its exact HEAD (default from .seven.toml)")
+	resume := fs.Bool("resume", false, "continue an init that failed part-way instead of refusing the unfinished sprite")
+	destroyOnFailure := fs.Bool("destroy-on-failure", false, "destroy the sprite if init fails instead of keeping it for --resume (for CI)")
+	timings := fs.Bool("timings", false, "report how long each init step took and the wall-clock time saved by running independent steps concurrently")
 	_ = fs.Parse(args)
 	if *newSprite && strings.TrimSpace(*spriteName) != "" {
 		fmt.Fprintln(os.Stderr, "seven init failed: --new and --sprite cannot be used together")
@@ -296,18 +468,48 @@ func cmdInit(args []string) {
 		fmt.Fprintf(os.Stderr, "seven init failed: %v\n", err)
 		os.Exit(1)
 	}
+	projectCfg, err := loadProjectConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven init failed: %v\n", err)
+		os.Exit(1)
+	}
+	projectCfg.Up.override(fs, preferredAssistant, *gstack, *fromHost)
+	if projectCfg.Up.Assistant == "" {
+		projectCfg.Up.Assistant = activeUserConfig.Settings.Assistant
+	}
 
-	_, err = runInit(upOptions{
+	var stepTimes *stepTimings
+	if *timings {
+		stepTimes = newStepTimings()
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	opts := upOptions{
 		Logger:         func(msg string) { fmt.Println(msg) },
 		QuietExternal:  false,
 		AssumeLoggedIn: *assumeLoggedIn,
 		OpenConsole:    false,
-		Assistant:      preferredAssistant,
+		Assistant:      projectCfg.Up.Assistant,
 		SpriteName:     strings.TrimSpace(*spriteName),
 		NewSprite:      *newSprite,
-		InstallGstack:  *gstack,
-		FromHost:       *fromHost,
-	})
+		InstallGstack:  projectCfg.Up.Gstack,
+		FromHost:       projectCfg.Up.FromHost,
+
+		Resume:           *resume,
+		DestroyOnFailure: *destroyOnFailure,
+
+		SkipCredentialSync: activeUserConfig.Settings.skippedSync(),
+		ClaudeOwnLogin:     activeUserConfig.Settings.ClaudeLogin == "sprite",
+		RequiredMCPServers: projectCfg.MCP.Required,
+		Timings:            stepTimes,
+	}
+	res, err := runInit(ctx, opts)
+	stepTimes.write(os.Stdout, "seven init")
+	// The profile kept the host's Claude credentials out, so the sprite needs
+	// a login of its own before anyone opens claude in it.
+	if err == nil && opts.ClaudeOwnLogin {
+		err = ensureSpriteClaudeLogin(ctx, res.Name, "[seven init]", false, opts)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "seven init failed: %v\n", err)
 		os.Exit(1)
@@ -361,23 +563,25 @@ func cmdDestroy(args []string) {
 		}
 	}
 
-	if err := ensureSpriteCLI(); err != nil {
+	if err := prepareBackend(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
-	if _, err := spriteList(); err != nil {
+	ctx, stop := interruptContext()
+	defer stop()
+	if _, err := spriteList(ctx); err != nil {
 		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	exists, err := spriteExists(name)
+	exists, err := spriteExists(ctx, name)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
 		os.Exit(1)
 	}
 	if exists {
-		if err := runCmd(spriteBin(), nil, "destroy", "--force", name); err != nil {
+		if err := activeBackend.Destroy(ctx, name); err != nil {
 			fmt.Fprintf(os.Stderr, "sprite destroy failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -402,6 +606,7 @@ func cmdDestroy(args []string) {
 
 func cmdStatus(args []string) {
 	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print machine-readable JSON")
 	_ = fs.Parse(args)
 
 	info, err := resolveSpriteName()
@@ -410,22 +615,31 @@ func cmdStatus(args []string) {
 		os.Exit(1)
 	}
 	name := info.Name
-	fromFile := info.FromFile
 
-	if err := ensureSpriteCLI(); err != nil {
+	if err := prepareBackend(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
-	exists, err := spriteExists(name)
+	ctx, stop := interruptContext()
+	defer stop()
+	exists, err := spriteExists(ctx, name)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	origin := "cwd"
-	if fromFile {
-		origin = ".sprite"
+	origin := selectionOrigin(info)
+	if *asJSON {
+		if err := writeJSON(statusJSON{
+			SchemaVersion: jsonSchemaVersion,
+			Origin:        origin,
+			Sprite:        newSpriteJSON(familyBase(info), name, info.FromFile, exists),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "seven status failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	if exists {
@@ -440,6 +654,7 @@ func cmdStatus(args []string) {
 
 func cmdList(args []string) {
 	fs := flag.NewFlagSet("ls", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print machine-readable JSON")
 	_ = fs.Parse(args)
 
 	info, err := resolveSpriteName()
@@ -447,23 +662,39 @@ func cmdList(args []string) {
 		fmt.Fprintf(os.Stderr, "failed to resolve sprite name: %v\n", err)
 		os.Exit(1)
 	}
-	if err := ensureSpriteCLI(); err != nil {
+	if err := prepareBackend(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	listOut, err := spriteList()
+	ctx, stop := interruptContext()
+	defer stop()
+	listOut, err := spriteList(ctx)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	base := info.Name
-	if info.FromFile {
-		base = spriteFamilyBase(info.Name)
-	}
+	base := familyBase(info)
 	selected := info.Name
 
 	members := spriteFamilyMembers(base, listOut)
+	if *asJSON {
+		doc := listJSON{
+			SchemaVersion: jsonSchemaVersion,
+			Family:        base,
+			Selected:      selected,
+			Origin:        selectionOrigin(info),
+			Sprites:       []spriteJSON{},
+		}
+		for _, name := range members {
+			doc.Sprites = append(doc.Sprites, newSpriteJSON(base, name, name == selected, true))
+		}
+		if err := writeJSON(doc); err != nil {
+			fmt.Fprintf(os.Stderr, "seven list failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	fmt.Printf("sprite family for %s:\n", base)
 	if len(members) == 0 {
 		fmt.Println("  (none yet — run 'seven up' to create the main sprite)")
@@ -486,17 +717,24 @@ func cmdList(args []string) {
 	fmt.Println("open:  seven up <number>    new:  seven up --new")
 }
 
-func runUp(opts upOptions) (upResult, error) {
+func runUp(ctx context.Context, opts upOptions) (result upResult, returnErr error) {
 	if opts.Logger == nil {
 		opts.Logger = func(string) {}
 	}
+	defer func() {
+		if returnErr != nil && ctx.Err() != nil && !errors.Is(returnErr, errInterrupted) {
+			returnErr = errInterrupted
+		}
+	}()
 
-	if err := ensureSpriteCLI(); err != nil {
+	if err := prepareBackend(); err != nil {
 		return upResult{}, err
 	}
-	maybeUpgradeSpriteCLI(opts)
+	if usesSpriteCLI() {
+		maybeUpgradeSpriteCLI(ctx, opts)
+	}
 
-	name, err := resolveTargetSpriteName(opts)
+	name, err := resolveTargetSpriteName(ctx, opts)
 	if err != nil {
 		return upResult{}, err
 	}
@@ -510,10 +748,10 @@ func runUp(opts upOptions) (upResult, error) {
 
 	opts.Logger(fmt.Sprintf("[seven up] using sprite name: %s", name))
 
-	exists, err := spriteExists(name)
+	exists, err := spriteExists(ctx, name)
 	if err != nil {
 		opts.Logger("[seven up] sprite list failed; running init")
-		res, initErr := runInit(opts)
+		res, initErr := runInit(ctx, opts)
 		if initErr != nil {
 			return upResult{}, initErr
 		}
@@ -522,6 +760,26 @@ func runUp(opts upOptions) (upResult, error) {
 	}
 	if exists {
 		opts.Logger("[seven up] sprite exists")
+		progress, err := readInitProgress(ctx, name)
+		if err != nil {
+			return upResult{}, err
+		}
+		if progress.Unfinished {
+			if !opts.Resume {
+				return upResult{}, unfinishedInitError(name, progress)
+			}
+			initOpts := opts
+			initOpts.ResolvedName = name
+			res, err := runInit(ctx, initOpts)
+			if err != nil {
+				return upResult{}, err
+			}
+			res.OpenConsole = opts.OpenConsole
+			return res, nil
+		}
+		if opts.Resume {
+			opts.Logger("[seven up] sprite is fully initialized; nothing to resume")
+		}
 		if err := writeSpriteFile(name); err != nil {
 			return upResult{}, err
 		}
@@ -529,22 +787,26 @@ func runUp(opts upOptions) (upResult, error) {
 		// set up once at creation. Re-syncing on every up was slow (per-call
 		// sprite-exec overhead adds up across config + auth for both assistants)
 		// and unnecessary for an established sprite. If a host token has rotated
-		// and the sprite copy is stale, run `claude` or `codex login` inside the
-		// sprite to re-auth — same recovery path as for gh.
-		assistantState := detectHostAssistantState(opts)
-		assistantState.PreferredAssistant = resolvePreferredAssistantInSprite(name, assistantState, "[seven up]", opts)
-		if err := configureConsoleBootstrapInSprite(name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
+		// and the sprite copy is stale, `seven sync-auth` copies it in again.
+		assistantState := detectHostAssistantState(ctx, opts)
+		assistantState.PreferredAssistant = resolvePreferredAssistantInSprite(ctx, name, assistantState, "[seven up]", opts)
+		if err := configureConsoleBootstrapInSprite(ctx, name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
 			opts.Logger(fmt.Sprintf("[seven up] console bootstrap setup failed: %v", err))
 		}
-		if err := reconcileProjectEnvironment(name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
-			return upResult{}, err
+		if err := reconcileProjectEnvironment(ctx, name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
+			return upResult{}, interruptedExistingSprite(ctx, name, err, opts)
+		}
+		if opts.AutoCheckpoint {
+			if err := autoCheckpoint(ctx, name, opts); err != nil {
+				return upResult{}, interruptedExistingSprite(ctx, name, err, opts)
+			}
 		}
 		return upResult{Name: name, OpenConsole: opts.OpenConsole, SpriteExists: true}, nil
 	}
 
 	initOpts := opts
 	initOpts.ResolvedName = name
-	res, err := runInit(initOpts)
+	res, err := runInit(ctx, initOpts)
 	if err != nil {
 		return upResult{}, err
 	}
@@ -552,18 +814,23 @@ func runUp(opts upOptions) (upResult, error) {
 	return res, nil
 }
 
-func runInit(opts upOptions) (result upResult, returnErr error) {
+func runInit(ctx context.Context, opts upOptions) (result upResult, returnErr error) {
 	if opts.Logger == nil {
 		opts.Logger = func(string) {}
 	}
+	defer func() {
+		if returnErr != nil && ctx.Err() != nil && !errors.Is(returnErr, errInterrupted) {
+			returnErr = errInterrupted
+		}
+	}()
 
-	if err := ensureSpriteCLI(); err != nil {
+	if err := prepareBackend(); err != nil {
 		return upResult{}, err
 	}
 
-	if !opts.AssumeLoggedIn {
+	if _, ok := activeBackend.(backend.Authenticator); ok && !opts.AssumeLoggedIn {
 		opts.Logger("[seven init] logging in to sprite")
-		if err := runCmd(spriteBin(), nil, "login"); err != nil {
+		if err := loginBackend(ctx); err != nil {
 			return upResult{}, err
 		}
 	}
@@ -574,7 +841,7 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 	}
 	name := opts.ResolvedName
 	if name == "" {
-		name, err = resolveTargetSpriteName(opts)
+		name, err = resolveTargetSpriteName(ctx, opts)
 		if err != nil {
 			return upResult{}, err
 		}
@@ -585,12 +852,24 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 
 	opts.Logger(fmt.Sprintf("[seven init] using sprite name: %s", name))
 
-	exists, err := spriteExists(name)
+	exists, err := spriteExists(ctx, name)
 	if err != nil {
 		return upResult{}, err
 	}
+	var progress initProgress
 	if exists {
 		opts.Logger("[seven init] sprite exists")
+		if progress, err = readInitProgress(ctx, name); err != nil {
+			return upResult{}, err
+		}
+		if progress.Unfinished && !opts.Resume {
+			return upResult{}, unfinishedInitError(name, progress)
+		}
+	}
+	if exists && !progress.Unfinished {
+		if opts.Resume {
+			opts.Logger("[seven init] sprite is fully initialized; nothing to resume")
+		}
 		if err := writeSpriteFile(name); err != nil {
 			return upResult{}, err
 		}
@@ -598,29 +877,34 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 		// set up once at creation. Re-syncing on every up was slow (per-call
 		// sprite-exec overhead adds up across config + auth for both assistants)
 		// and unnecessary for an established sprite. If a host token has rotated
-		// and the sprite copy is stale, run `claude` or `codex login` inside the
-		// sprite to re-auth — same recovery path as for gh.
-		assistantState := detectHostAssistantState(opts)
-		assistantState.PreferredAssistant = resolvePreferredAssistantInSprite(name, assistantState, "[seven init]", opts)
-		if err := configureConsoleBootstrapInSprite(name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
+		// and the sprite copy is stale, `seven sync-auth` copies it in again.
+		assistantState := detectHostAssistantState(ctx, opts)
+		assistantState.PreferredAssistant = resolvePreferredAssistantInSprite(ctx, name, assistantState, "[seven init]", opts)
+		if err := configureConsoleBootstrapInSprite(ctx, name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
 			opts.Logger(fmt.Sprintf("[seven init] console bootstrap setup failed: %v", err))
 		}
-		if err := reconcileProjectEnvironment(name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
+		if err := reconcileProjectEnvironment(ctx, name, spriteFamilyBase(name), assistantState.PreferredAssistant, opts); err != nil {
 			return upResult{}, err
 		}
 		return upResult{Name: name, OpenConsole: false, SpriteExists: true}, nil
 	}
+	resuming := progress.Unfinished
+	if resuming {
+		opts.Logger(fmt.Sprintf("[seven init] resuming unfinished init (last completed step: %s)", progress.last()))
+	} else if opts.Resume {
+		opts.Logger("[seven init] no sprite to resume; creating a new one")
+	}
 
 	// By default, the host checkout identifies only the repository: a fresh
 	// Sprite clones the remote default branch. Exact host branch/HEAD coupling is
 	// an explicit escape hatch for testing pushed work before merge.
-	repoURL, repoSlug, ghToken, err := detectRepoInfo(name, opts)
+	repoURL, repoSlug, ghToken, err := detectRepoInfo(ctx, name, opts)
 	if err != nil {
 		return upResult{}, err
 	}
 	repoBranch, repoHead := "", ""
 	if repoURL != "" && opts.FromHost {
-		repoBranch, repoHead, err = detectRepoCheckout(opts)
+		repoBranch, repoHead, err = detectRepoCheckout(ctx, opts)
 		if err != nil {
 			return upResult{}, err
 		}
@@ -636,20 +920,50 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 		return upResult{}, selectionErr
 	}
 
-	opts.Logger("[seven init] creating sprite")
-	if opts.QuietExternal {
-		if err := runCmdQuiet(spriteBin(), nil, "create", "--skip-console", name); err != nil {
+	if !resuming {
+		opts.Logger("[seven init] creating sprite")
+		began := time.Now()
+		if err := activeBackend.Create(ctx, name); err != nil {
+			if ctx.Err() != nil {
+				return upResult{}, discardInterruptedCreate(ctx, name, opts)
+			}
 			return upResult{}, err
 		}
-	} else if err := runCmdDevNull(spriteBin(), nil, "create", "--skip-console", name); err != nil {
-		return upResult{}, err
+		opts.Timings.record("create", began, false)
 	}
+	// A failed init keeps the sprite and records how far it got, so `seven up
+	// --resume` can continue instead of repeating a long clone or download.
+	// DestroyOnFailure restores the clean slate CI wants. An interrupt takes
+	// the same path; the cleanup runs on a context of its own, since the
+	// interrupt cancelled ctx.
 	defer func() {
 		if returnErr == nil {
+			if err := clearInitProgress(ctx, name); err != nil {
+				returnErr = err
+			}
 			return
 		}
-		opts.Logger(fmt.Sprintf("[seven init] initialization failed; destroying incomplete sprite: %s", name))
-		if cleanupErr := runCmd(spriteBin(), nil, "destroy", "--force", name); cleanupErr != nil {
+		outcome := "failed"
+		if ctx.Err() != nil {
+			outcome = "was interrupted"
+			// The step's own error only says how its child process died.
+			returnErr = fmt.Errorf("%w after init step %s", errInterrupted, progress.last())
+		}
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		if !opts.DestroyOnFailure {
+			// The sprite exists even when recording its creation was cut short.
+			if !progress.has(initStepCreated) {
+				progress.Done = append([]string{initStepCreated}, progress.Done...)
+			}
+			if err := writeInitProgress(cleanupCtx, name, progress); err != nil {
+				returnErr = errors.Join(returnErr, err)
+			}
+			opts.Logger(fmt.Sprintf("[seven init] initialization %s after step %s; kept sprite %s (run `seven up --resume` to continue, or `seven destroy` to discard it)", outcome, progress.last(), name))
+			return
+		}
+		opts.Logger(fmt.Sprintf("[seven init] initialization %s; destroying incomplete sprite: %s", outcome, name))
+		if cleanupErr := activeBackend.Destroy(cleanupCtx, name); cleanupErr != nil {
 			returnErr = errors.Join(returnErr, fmt.Errorf("destroy incomplete sprite %s: %w", name, cleanupErr))
 		}
 		if hadPreviousSelection {
@@ -665,19 +979,66 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 	if err := writeSpriteFile(name); err != nil {
 		return upResult{}, err
 	}
-
-	if err := syncGitIdentity(name, opts); err != nil {
+	if err := progress.run(ctx, initStepCreated, opts, func() error {
+		// Mark the sprite unfinished before any provisioning, so even a run
+		// that is killed outright leaves a sprite that --resume recognizes.
+		return writeInitProgress(ctx, name, initProgress{Done: []string{initStepCreated}})
+	}); err != nil {
 		return upResult{}, err
 	}
 
-	assistantState := detectHostAssistantState(opts)
-	if err := ensureGhAuthInSprite(name, ghToken, opts); err != nil {
-		opts.Logger(fmt.Sprintf("[seven init] gh auth setup failed: %v", err))
+	// Identity, gh auth, and the assistant file sync are independent sprite
+	// execs, so they run as a step graph instead of paying exec latency one
+	// after another. gh auth follows identity because both write ~/.gitconfig,
+	// and git refuses a second concurrent writer.
+	assistantState := detectHostAssistantState(ctx, opts)
+	resumable := func(step string, fn func(ctx context.Context, opts upOptions) error) func(context.Context, upOptions) error {
+		return func(ctx context.Context, stepOpts upOptions) error {
+			if progress.has(step) {
+				stepOpts.Logger(fmt.Sprintf("[seven init] step already done: %s", step))
+				return nil
+			}
+			return fn(ctx, stepOpts)
+		}
+	}
+	graph := []graphStep{
+		{Name: initStepIdentity, Run: resumable(initStepIdentity, func(ctx context.Context, opts upOptions) error {
+			return syncGitIdentity(ctx, name, opts)
+		})},
+		{Name: initStepGhAuth, After: []string{initStepIdentity}, Run: resumable(initStepGhAuth, func(ctx context.Context, opts upOptions) error {
+			if err := ensureGhAuthInSprite(ctx, name, ghToken, "[seven init]", opts); err != nil {
+				opts.Logger(fmt.Sprintf("[seven init] gh auth setup failed: %v", err))
+			}
+			return nil
+		})},
+		{Name: initStepAssistantSync, Run: func(ctx context.Context, opts upOptions) error {
+			if progress.has(initStepAssistantSync) {
+				opts.Logger(fmt.Sprintf("[seven init] step already done: %s", initStepAssistantSync))
+				assistantState.PreferredAssistant = resolvePreferredAssistantInSprite(ctx, name, assistantState, "[seven init]", opts)
+				return nil
+			}
+			assistantState = syncHostAssistantState(ctx, name, assistantState, "[seven init]", opts)
+			// MCP servers go in after the assistant files they live in.
+			if _, err := syncMCPServers(ctx, name, "[seven init]", opts); err != nil {
+				return fmt.Errorf("mcp sync: %w", err)
+			}
+			return nil
+		}},
+	}
+	completed, err := runStepGraph(ctx, graph, opts)
+	for _, step := range initSteps {
+		if slices.Contains(completed, step) && !progress.has(step) {
+			progress.Done = append(progress.Done, step)
+		}
+	}
+	if err != nil {
+		return upResult{}, err
 	}
-	assistantState = syncHostAssistantState(name, assistantState, "[seven init]", opts)
 
 	if repoURL == "" {
-		if err := maybeInstallGstack(name, assistantState.PreferredAssistant, gstackDefaultRevision, opts); err != nil {
+		if err := progress.run(ctx, initStepTooling, opts, func() error {
+			return maybeInstallGstack(ctx, name, assistantState.PreferredAssistant, gstackDefaultRevision, opts)
+		}); err != nil {
 			return upResult{}, fmt.Errorf("required gstack provisioning failed: %w", err)
 		}
 		opts.Logger("[seven init] no repo url found, skipping clone")
@@ -686,36 +1047,91 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 	// Clone into a directory named after the repo (the sprite family base), not
 	// the sprite name, so sibling sprites get "soclimmo" rather than "soclimmo-02".
 	repoDir := spriteFamilyBase(name)
+	// The git clone path has always pointed the console at the sprite name.
+	bootstrapDir := repoDir
+	if repoSlug == "" {
+		bootstrapDir = name
+	}
+
+	if err := progress.run(ctx, initStepCloned, opts, func() error {
+		if resuming {
+			// The clone step did not finish, so whatever it left behind is
+			// not trusted; clone again from scratch.
+			if err := spriteExec(ctx, name, nil, true, "sh", "-lc", `rm -rf "$HOME/$1"`, "sh", repoDir); err != nil {
+				return fmt.Errorf("remove partial clone: %w", err)
+			}
+		}
+		if err := cloneRepoInSprite(ctx, name, repoURL, repoSlug, repoBranch, repoDir, ghToken, opts); err != nil {
+			return err
+		}
+		return verifyClonedRepoHead(ctx, name, repoDir, repoHead)
+	}); err != nil {
+		return upResult{}, err
+	}
+	if err := progress.run(ctx, initStepBootstrap, opts, func() error {
+		if err := configureConsoleBootstrapInSprite(ctx, name, bootstrapDir, assistantState.PreferredAssistant, opts); err != nil {
+			opts.Logger(fmt.Sprintf("[seven init] console bootstrap setup failed: %v", err))
+		}
+		return nil
+	}); err != nil {
+		return upResult{}, err
+	}
+	if err := progress.run(ctx, initStepTooling, opts, func() error {
+		return reconcileProjectEnvironment(ctx, name, repoDir, assistantState.PreferredAssistant, opts)
+	}); err != nil {
+		return upResult{}, err
+	}
+
+	return upResult{Name: name, OpenConsole: false, SpriteExists: false}, nil
+}
+
+// interruptedExistingSprite reports an interrupt that stopped `seven up` on
+// an already initialized sprite; err is returned as is otherwise.
+func interruptedExistingSprite(ctx context.Context, name string, err error, opts upOptions) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	opts.Logger(fmt.Sprintf("[seven up] interrupted; kept initialized sprite %s (its project tooling may be partly reconciled until the next `seven up`)", name))
+	return errInterrupted
+}
+
+// discardInterruptedCreate removes a sprite whose create was interrupted: it
+// has no progress marker yet, so keeping it would pass for a finished init.
+func discardInterruptedCreate(ctx context.Context, name string, opts upOptions) error {
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	exists, err := spriteExists(cleanupCtx, name)
+	if err != nil {
+		opts.Logger(fmt.Sprintf("[seven init] sprite create was interrupted; could not check whether %s exists (run `seven destroy` if it does)", name))
+		return fmt.Errorf("%w while creating sprite %s: %w", errInterrupted, name, err)
+	}
+	if !exists {
+		opts.Logger("[seven init] sprite create was interrupted; no sprite was left behind")
+		return fmt.Errorf("%w while creating sprite %s", errInterrupted, name)
+	}
+	opts.Logger(fmt.Sprintf("[seven init] sprite create was interrupted; destroying partial sprite: %s", name))
+	if err := activeBackend.Destroy(cleanupCtx, name); err != nil {
+		return fmt.Errorf("%w while creating sprite %s; destroy it failed: %w", errInterrupted, name, err)
+	}
+	return fmt.Errorf("%w while creating sprite %s", errInterrupted, name)
+}
 
+// cloneRepoInSprite clones the host repo into repoDir: through gh for GitHub
+// remotes, so a private repo works with the synced token, else with git.
+func cloneRepoInSprite(ctx context.Context, name, repoURL, repoSlug, repoBranch, repoDir, ghToken string, opts upOptions) error {
 	if repoSlug != "" {
 		cloneArgs := []string{"repo", "clone", repoSlug, repoDir}
 		if repoBranch != "" {
 			cloneArgs = append(cloneArgs, "--", "--branch", repoBranch)
 			opts.Logger(fmt.Sprintf("[seven init] cloning current host branch: %s", repoBranch))
 		}
+		commandArgs := append([]string{"gh"}, cloneArgs...)
 		if ghToken != "" {
 			opts.Logger(fmt.Sprintf("[seven init] cloning via gh repo clone: %s", repoSlug))
-			commandArgs := append([]string{"gh"}, cloneArgs...)
-			if err := spriteExec(name, []string{"GH_TOKEN=" + ghToken}, opts.QuietExternal, commandArgs...); err != nil {
-				return upResult{}, err
-			}
-		} else {
-			opts.Logger(fmt.Sprintf("[seven init] cloning via gh repo clone (no token): %s", repoSlug))
-			commandArgs := append([]string{"gh"}, cloneArgs...)
-			if err := spriteExec(name, nil, opts.QuietExternal, commandArgs...); err != nil {
-				return upResult{}, err
-			}
-		}
-		if err := verifyClonedRepoHead(name, repoDir, repoHead); err != nil {
-			return upResult{}, err
+			return spriteExec(ctx, name, []string{"GH_TOKEN=" + ghToken}, opts.QuietExternal, commandArgs...)
 		}
-		if err := configureConsoleBootstrapInSprite(name, repoDir, assistantState.PreferredAssistant, opts); err != nil {
-			opts.Logger(fmt.Sprintf("[seven init] console bootstrap setup failed: %v", err))
-		}
-		if err := reconcileProjectEnvironment(name, repoDir, assistantState.PreferredAssistant, opts); err != nil {
-			return upResult{}, err
-		}
-		return upResult{Name: name, OpenConsole: false, SpriteExists: false}, nil
+		opts.Logger(fmt.Sprintf("[seven init] cloning via gh repo clone (no token): %s", repoSlug))
+		return spriteExec(ctx, name, nil, opts.QuietExternal, commandArgs...)
 	}
 
 	opts.Logger(fmt.Sprintf("[seven init] cloning via git clone: %s", repoURL))
@@ -725,66 +1141,69 @@ func runInit(opts upOptions) (result upResult, returnErr error) {
 		opts.Logger(fmt.Sprintf("[seven init] cloning current host branch: %s", repoBranch))
 	}
 	cloneArgs = append(cloneArgs, repoURL, repoDir)
-	commandArgs := append([]string{"git"}, cloneArgs...)
-	if err := spriteExec(name, nil, opts.QuietExternal, commandArgs...); err != nil {
-		return upResult{}, err
-	}
-	if err := verifyClonedRepoHead(name, repoDir, repoHead); err != nil {
-		return upResult{}, err
-	}
-	if err := configureConsoleBootstrapInSprite(name, name, assistantState.PreferredAssistant, opts); err != nil {
-		opts.Logger(fmt.Sprintf("[seven init] console bootstrap setup failed: %v", err))
-	}
-	if err := reconcileProjectEnvironment(name, repoDir, assistantState.PreferredAssistant, opts); err != nil {
-		return upResult{}, err
-	}
-
-	return upResult{Name: name, OpenConsole: false, SpriteExists: false}, nil
+	return spriteExec(ctx, name, nil, opts.QuietExternal, append([]string{"git"}, cloneArgs...)...)
 }
 
-func runUpWithTUI(opts upOptions) (upResult, error) {
+func runUpWithTUI(ctx context.Context, opts upOptions) (upResult, error) {
 	if !opts.AssumeLoggedIn {
-		if err := ensureSpriteCLI(); err != nil {
+		if err := prepareBackend(); err != nil {
 			return upResult{}, err
 		}
-		if _, err := spriteList(); err != nil {
+		if _, err := spriteList(ctx); err != nil {
 			fmt.Println(formatStyledBulletLog("[seven init] logging in to sprite"))
-			if err := runCmd(spriteBin(), nil, "login"); err != nil {
+			if err := loginBackend(ctx); err != nil {
 				return upResult{}, err
 			}
 		}
 		opts.AssumeLoggedIn = true
 	}
 
-	m := newUpModel()
-	p := tea.NewProgram(m)
+	// Ctrl-C is a keystroke in the TUI's raw mode, so the model cancels ctx
+	// itself and stays up to show the cleanup.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p := tea.NewProgram(newUpModel(cancel))
 
+	var logMu sync.Mutex
+	tuiExited := false
+	done := make(chan doneMsg, 1)
 	go func() {
 		runOpts := opts
-		runOpts.Logger = func(msg string) { p.Send(logMsg(msg)) }
+		runOpts.Logger = func(msg string) {
+			logMu.Lock()
+			defer logMu.Unlock()
+			if tuiExited {
+				fmt.Println(formatStyledBulletLog(msg))
+				return
+			}
+			p.Send(logMsg(msg))
+		}
 		// TUI mode captures output for cleaner display.
 		runOpts.QuietExternal = true
-		res, err := runUp(runOpts)
+		res, err := runUp(ctx, runOpts)
+		done <- doneMsg{res: res, err: err}
 		p.Send(doneMsg{res: res, err: err})
 	}()
 
-	final, err := p.Run()
-	if err != nil {
+	_, err := p.Run()
+	// A signal makes the TUI exit before runUp returns. Stop the run and wait
+	// for its cleanup, whose log lines now go straight to stdout.
+	cancel()
+	logMu.Lock()
+	tuiExited = true
+	logMu.Unlock()
+	result := <-done
+	if err != nil && !errors.Is(err, tea.ErrInterrupted) {
 		return upResult{}, err
 	}
-	fm, ok := final.(upModel)
-	if !ok {
-		return upResult{}, errors.New("unexpected TUI model")
-	}
-	if fm.err != nil {
-		return upResult{}, fm.err
-	}
-	return fm.res, nil
+	return result.res, result.err
 }
 
+// runConsole is never cancelled: Ctrl-C inside the console belongs to the
+// sprite's shell.
 func runConsole(name string) error {
 	fmt.Println(formatStyledBulletLog(fmt.Sprintf("[seven up] opening console: %s", name)))
-	return runCmd(spriteBin(), nil, "console", "-s", name)
+	return activeBackend.Console(context.Background(), name)
 }
 
 func resolveSpriteName() (spriteNameInfo, error) {
@@ -814,6 +1233,13 @@ func resolveSpriteName() (spriteNameInfo, error) {
 		}
 		return info, nil
 	}
+	projectCfg, err := loadProjectConfig()
+	if err != nil {
+		return spriteNameInfo{}, err
+	}
+	if projectCfg.Sprite.Name != "" {
+		return spriteNameInfo{Name: projectCfg.Sprite.Name, FromConfig: true, Original: projectCfg.Sprite.Name}, nil
+	}
 
 	normalized := normalizeSpriteName(info.Name)
 	info.Name = normalized
@@ -824,7 +1250,7 @@ func resolveSpriteName() (spriteNameInfo, error) {
 	return info, nil
 }
 
-func resolveTargetSpriteName(opts upOptions) (string, error) {
+func resolveTargetSpriteName(ctx context.Context, opts upOptions) (string, error) {
 	if opts.ResolvedName != "" {
 		return opts.ResolvedName, nil
 	}
@@ -841,11 +1267,7 @@ func resolveTargetSpriteName(opts upOptions) (string, error) {
 	}
 
 	if opts.SiblingOrdinal > 0 {
-		base := info.Name
-		if info.FromFile {
-			base = spriteFamilyBase(info.Name)
-		}
-		name := siblingSpriteNameForOrdinal(base, opts.SiblingOrdinal)
+		name := siblingSpriteNameForOrdinal(familyBase(info), opts.SiblingOrdinal)
 		if err := validateSpriteName(name); err != nil {
 			return "", err
 		}
@@ -856,15 +1278,64 @@ func resolveTargetSpriteName(opts upOptions) (string, error) {
 		return info.Name, nil
 	}
 
-	base := info.Name
+	listOut, err := spriteList(ctx)
+	if err != nil {
+		return "", err
+	}
+	return nextSiblingSpriteName(familyBase(info), listOut), nil
+}
+
+// familyBase returns the main sprite name of the family info belongs to. A
+// name from .sprite may be a sibling; a name from .seven.toml or the checkout
+// directory is always the main sprite.
+func familyBase(info spriteNameInfo) string {
 	if info.FromFile {
-		base = spriteFamilyBase(info.Name)
+		return spriteFamilyBase(info.Name)
 	}
-	listOut, err := spriteList()
+	return info.Name
+}
+
+// resolveExistingSprite resolves the target exactly like seven up (explicit
+// --sprite, sibling #N, or the selected sprite) for commands that operate on a
+// sprite that must already exist. It exits on failure.
+func resolveExistingSprite(ctx context.Context, command string, opts upOptions) string {
+	name, err := resolveTargetSpriteName(ctx, opts)
 	if err != nil {
-		return "", err
+		fmt.Fprintf(os.Stderr, "failed to resolve sprite name: %v\n", err)
+		os.Exit(1)
+	}
+	if err := prepareBackend(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
 	}
-	return nextSiblingSpriteName(base, listOut), nil
+	exists, err := spriteExists(ctx, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
+		os.Exit(1)
+	}
+	if !exists {
+		fmt.Fprintf(os.Stderr, "seven %s failed: sprite not found: %s (run 'seven up' first)\n", command, name)
+		os.Exit(1)
+	}
+	return name
+}
+
+// parseSpriteOrdinal strips an optional leading number (e.g. "seven up 2")
+// that selects sibling #N. It must come first so it is never confused with a
+// flag value like "--sprite 2".
+func parseSpriteOrdinal(command string, args []string) (int, []string) {
+	if len(args) == 0 {
+		return 0, args
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, args
+	}
+	if n < 1 {
+		fmt.Fprintf(os.Stderr, "seven %s failed: sprite number must be a positive integer, got %q\n", command, args[0])
+		os.Exit(1)
+	}
+	return n, args[1:]
 }
 
 // siblingSpriteNameForOrdinal maps a 1-based family ordinal to a sprite name:
@@ -1044,14 +1515,14 @@ func ensureSpriteCLI() error {
 	return errors.New("sprite CLI not found after install; ensure ~/.local/bin is on PATH")
 }
 
-func maybeUpgradeSpriteCLI(opts upOptions) {
-	if os.Getenv("SEVEN_SKIP_SPRITE_UPGRADE") == "1" {
+func maybeUpgradeSpriteCLI(ctx context.Context, opts upOptions) {
+	if os.Getenv("SEVEN_SKIP_SPRITE_UPGRADE") == "1" || opts.SkipSpriteUpgrade {
 		opts.Logger("[seven up] skipping sprite CLI update check")
 		return
 	}
 
 	opts.Logger("[seven up] checking sprite CLI updates")
-	out, err := runCmdOutput(spriteBin(), nil, "upgrade", "--check")
+	out, err := runCmdOutput(ctx, spriteBin(), nil, "upgrade", "--check")
 	if err != nil {
 		opts.Logger(fmt.Sprintf("[seven up] sprite upgrade check failed: %v", err))
 		return
@@ -1068,7 +1539,7 @@ func maybeUpgradeSpriteCLI(opts upOptions) {
 	}
 
 	opts.Logger(fmt.Sprintf("[seven up] upgrading sprite CLI from %s to %s", current, latest))
-	if err := runCmdWithInput(spriteBin(), nil, "y\n", "upgrade"); err != nil {
+	if err := runCmdWithInput(ctx, spriteBin(), nil, "y\n", "upgrade"); err != nil {
 		opts.Logger(fmt.Sprintf("[seven up] sprite CLI upgrade failed: %v", err))
 		return
 	}
@@ -1094,8 +1565,8 @@ func parseSpriteUpgradeCheckOutput(out string) (latest, current string, ok bool)
 	return strings.TrimSpace(latestMatch[1]), strings.TrimSpace(currentMatch[1]), true
 }
 
-func configureConsoleBootstrapInSprite(spriteName, repoDir, assistant string, opts upOptions) error {
-	if err := configureSpriteIdentity(spriteName, opts); err != nil {
+func configureConsoleBootstrapInSprite(ctx context.Context, spriteName, repoDir, assistant string, opts upOptions) error {
+	if err := configureSpriteIdentity(ctx, spriteName, opts); err != nil {
 		opts.Logger(fmt.Sprintf("[seven init] sprite identity setup failed: %v", err))
 	}
 
@@ -1104,7 +1575,7 @@ func configureConsoleBootstrapInSprite(spriteName, repoDir, assistant string, op
 	}
 
 	opts.Logger(fmt.Sprintf("[seven init] configuring first console launch: cd %s (assistant: %s)", repoDir, assistant))
-	env := []string{"SEVEN_REPO_DIR=" + repoDir + ",SEVEN_ASSISTANT=" + assistant}
+	env := []string{"SEVEN_REPO_DIR=" + repoDir, "SEVEN_ASSISTANT=" + assistantCommands[assistant]}
 	cmd := `set -e
 cat > "` + sevenConsoleHookPath + `" <<'EOF'
 # seven one-shot console bootstrap
@@ -1174,7 +1645,7 @@ done
 printf '%s\n%s\n' "$HOME/$SEVEN_REPO_DIR" "$SEVEN_ASSISTANT" > "` + sevenConsoleMarkerPath + `"
 chmod 600 "` + sevenConsoleMarkerPath + `"
 `
-	return spriteExec(spriteName, env, opts.QuietExternal, "sh", "-lc", cmd)
+	return spriteExec(ctx, spriteName, env, opts.QuietExternal, "sh", "-lc", cmd)
 }
 
 // maybeInstallGstack installs or repairs gstack inside the sprite when explicitly
@@ -1182,7 +1653,7 @@ chmod 600 "` + sevenConsoleMarkerPath + `"
 // checkout takes a read-only fast path, so routine seven up calls do not redownload
 // the checkout, dependencies, and browser. Setup targets every supported assistant
 // present in the sprite, so Claude Code and Codex share one checkout.
-func maybeInstallGstack(spriteName, assistant, revision string, opts upOptions) error {
+func maybeInstallGstack(ctx context.Context, spriteName, assistant, revision string, opts upOptions) error {
 	if !opts.InstallGstack {
 		return nil
 	}
@@ -1191,7 +1662,7 @@ func maybeInstallGstack(spriteName, assistant, revision string, opts upOptions)
 		return fmt.Errorf("gstack revision must be a full commit SHA, got %q", revision)
 	}
 
-	if err := spriteExec(spriteName, nil, true, "sh", "-lc", "command -v bun >/dev/null 2>&1"); err != nil {
+	if err := spriteExec(ctx, spriteName, nil, true, "sh", "-lc", "command -v bun >/dev/null 2>&1"); err != nil {
 		return fmt.Errorf("bun is required for gstack but is absent from the Sprite image")
 	}
 
@@ -1201,7 +1672,7 @@ func maybeInstallGstack(spriteName, assistant, revision string, opts upOptions)
 	// only Git/file metadata. Any missing invariant falls through to a fresh,
 	// verified replacement below.
 	probe := gstackHealthProbe(revision)
-	if err := spriteExec(spriteName, nil, true, "sh", "-lc", probe); err == nil {
+	if err := spriteExec(ctx, spriteName, nil, true, "sh", "-lc", probe); err == nil {
 		opts.Logger("[seven init] gstack already healthy — skipping setup and browser download")
 		return nil
 	}
@@ -1241,7 +1712,7 @@ bun install --frozen-lockfile
 	// Gstack reconciliation can take minutes. Use the CLI's non-TTY HTTP
 	// transport so waking an existing Sprite does not depend on a long-lived
 	// WebSocket connection.
-	out, err := spriteExecOutputHTTPPost(spriteName, nil, "sh", "-lc", install)
+	out, err := spriteExecOutputLongRunning(ctx, spriteName, nil, "sh", "-lc", install)
 	if err != nil && strings.Contains(out, "Error: no exit frame received") {
 		// The Sprite CLI's HTTP POST transport can stream the complete command
 		// output and still exit 1 because it did not observe the protocol's final
@@ -1249,7 +1720,7 @@ bun install --frozen-lockfile
 		// transport: a completed first run becomes a quick verification pass,
 		// while a real setup failure remains fatal on the retry.
 		opts.Logger("[seven init] gstack transport lost its exit frame; retrying setup once")
-		retryOut, retryErr := spriteExecOutput(spriteName, nil, "sh", "-lc", install)
+		retryOut, retryErr := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", install)
 		if retryErr != nil {
 			return fmt.Errorf("gstack setup retry failed after missing exit frame: %w%s", retryErr, gstackOutputTail(retryOut))
 		}
@@ -1325,7 +1796,7 @@ func gstackOutputTail(out string) string {
 
 // projectToolingManifestRelPath is the conventional path, within a cloned repo, of a project's
 // declarative tooling manifest. A project opts into auto-install simply by shipping this file —
-// one tool per line: "kind name npm-spec(pinned) verify-command". This keeps seven entirely
+// one tool per line: "kind name pinned-spec verify-command". This keeps seven entirely
 // project-agnostic: it hardcodes no project's dependencies, it just honors whatever the pulled
 // repo declares.
 const projectToolingManifestRelPath = "scripts/sprite-tooling.manifest"
@@ -1334,6 +1805,19 @@ var toolingNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
 var pythonModulePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
 var toolingVersionPattern = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+$`)
 var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// aptPackagePattern and aptVersionPattern accept Debian package names and
+// versions (epochs, revisions, "~" and "+" included) but no shell
+// metacharacters, since apt rows reach sudo apt-get.
+var aptPackagePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.+-]+$`)
+var aptVersionPattern = regexp.MustCompile(`^[0-9][A-Za-z0-9.+~:-]*$`)
+
+// goPackagePattern accepts a Go package path under a domain (go install
+// rejects anything else for @version installs); crateNamePattern is what
+// crates.io allows.
+var goPackagePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*(\.[a-z0-9-]+)+(/[A-Za-z0-9][A-Za-z0-9._~-]*)+$`)
+var goMajorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+var crateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
 var forbiddenToolNames = map[string]bool{
 	"alias": true, "break": true, "cd": true, "command": true, "continue": true,
 	"echo": true, "eval": true, "exec": true, "export": true, "false": true,
@@ -1355,10 +1839,10 @@ func (manifest validatedToolingManifest) normalized() string {
 // readProjectToolingManifest reads and validates the complete manifest before
 // any install mechanism runs. Parsing in Go avoids shell-evaluation hazards and
 // catches duplicate, malformed, unknown, and non-newline-terminated rows.
-func readProjectToolingManifest(spriteName, repoDir string) (validatedToolingManifest, bool, error) {
+func readProjectToolingManifest(ctx context.Context, spriteName, repoDir string) (validatedToolingManifest, bool, error) {
 	manifestPath := "$HOME/" + repoDir + "/" + projectToolingManifestRelPath
 	presenceCmd := `if [ -f "` + manifestPath + `" ]; then printf 'present'; elif [ -e "` + manifestPath + `" ]; then exit 2; else printf 'absent'; fi`
-	presence, err := spriteExecOutput(spriteName, nil, "sh", "-lc", presenceCmd)
+	presence, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", presenceCmd)
 	if err != nil {
 		return validatedToolingManifest{}, false, fmt.Errorf("probe project tooling manifest: %w", err)
 	}
@@ -1369,7 +1853,7 @@ func readProjectToolingManifest(spriteName, repoDir string) (validatedToolingMan
 	default:
 		return validatedToolingManifest{}, false, fmt.Errorf("probe project tooling manifest: unexpected response %q", strings.TrimSpace(presence))
 	}
-	out, err := spriteExecOutput(spriteName, nil, "sh", "-lc", `cat "`+manifestPath+`"`)
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", `cat "`+manifestPath+`"`)
 	if err != nil {
 		return validatedToolingManifest{}, true, fmt.Errorf("read project tooling manifest: %w", err)
 	}
@@ -1434,13 +1918,13 @@ func parseProjectToolingManifest(contents string) (validatedToolingManifest, err
 			if !strings.HasPrefix(packageSpec, prefix) || !toolingVersionPattern.MatchString(strings.TrimPrefix(packageSpec, prefix)) {
 				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: npm spec must be an exact name@version pin", index+1)
 			}
-		case "pip":
+		case "pip", "uv-tool":
 			if verifyName != name || (verifyArg != "--version" && verifyArg != "version") {
 				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsafe verifier", index+1)
 			}
 			prefix := name + "=="
 			if !strings.HasPrefix(packageSpec, prefix) || !toolingVersionPattern.MatchString(strings.TrimPrefix(packageSpec, prefix)) {
-				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: pip spec must be an exact name==version pin", index+1)
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: %s spec must be an exact name==version pin", index+1, kind)
 			}
 		case "pip-module":
 			prefix := name + "=="
@@ -1454,7 +1938,8 @@ func parseProjectToolingManifest(contents string) (validatedToolingManifest, err
 				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsafe verifier", index+1)
 			}
 			parts := strings.Split(packageSpec, "|")
-			if len(parts) != 6 || !toolingVersionPattern.MatchString(parts[0]) ||
+			if (len(parts) != 6 && len(parts) != 7) || !toolingVersionPattern.MatchString(parts[0]) ||
+				(len(parts) == 7 && !archiveFormats[parts[6]]) ||
 				!validArchiveURLTemplate(parts[1]) ||
 				!sha256Pattern.MatchString(parts[2]) || !sha256Pattern.MatchString(parts[3]) ||
 				!validArchiveMember(parts[4]) {
@@ -1470,6 +1955,40 @@ func parseProjectToolingManifest(contents string) (validatedToolingManifest, err
 					}
 				}
 			}
+		case "apt":
+			// The package rarely shares its name with the binary
+			// (postgresql-client ships psql), so the verifier may differ.
+			if !toolingNamePattern.MatchString(verifyName) || forbiddenToolNames[verifyName] ||
+				(verifyArg != "--version" && verifyArg != "version") {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsafe verifier", index+1)
+			}
+			prefix := name + "="
+			if !aptPackagePattern.MatchString(name) || !strings.HasPrefix(packageSpec, prefix) ||
+				!aptVersionPattern.MatchString(strings.TrimPrefix(packageSpec, prefix)) {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: apt spec must be an exact name=version pin", index+1)
+			}
+		case "go":
+			if verifyName != name || (verifyArg != "--version" && verifyArg != "version") {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsafe verifier", index+1)
+			}
+			at := strings.LastIndex(packageSpec, "@")
+			if at < 0 || !goPackagePattern.MatchString(packageSpec[:at]) || goBinaryName(packageSpec[:at]) != name ||
+				!strings.HasPrefix(packageSpec[at+1:], "v") || !toolingVersionPattern.MatchString(packageSpec[at+1:]) {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: go spec must be an exact package@vX.Y.Z pin whose binary is %q", index+1, name)
+			}
+		case "cargo":
+			// Like apt, a crate's binary may be named differently (ripgrep
+			// ships rg); cargo install --list proves the exact version.
+			if !toolingNamePattern.MatchString(verifyName) || forbiddenToolNames[verifyName] ||
+				(verifyArg != "--version" && verifyArg != "version") {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsafe verifier", index+1)
+			}
+			prefix := name + "@"
+			version := strings.TrimPrefix(packageSpec, prefix)
+			if !crateNamePattern.MatchString(name) || !strings.HasPrefix(packageSpec, prefix) ||
+				strings.HasPrefix(version, "v") || !toolingVersionPattern.MatchString(version) {
+				return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: cargo spec must be an exact crate@version pin", index+1)
+			}
 		default:
 			return validatedToolingManifest{}, fmt.Errorf("invalid project tooling manifest line %d: unsupported kind %q", index+1, kind)
 		}
@@ -1478,6 +1997,22 @@ func parseProjectToolingManifest(contents string) (validatedToolingManifest, err
 	return manifest, nil
 }
 
+// goBinaryName is the file go install writes for a package path: its last
+// element, skipping a trailing major-version suffix such as /v2.
+func goBinaryName(packagePath string) string {
+	elems := strings.Split(packagePath, "/")
+	last := elems[len(elems)-1]
+	if goMajorVersionPattern.MatchString(last) && len(elems) > 2 {
+		last = elems[len(elems)-2]
+	}
+	return last
+}
+
+// archiveFormats are the values an archive row's optional seventh field may
+// name. Without it the format comes from the URL's extension (.zip, .tar.xz or
+// .txz), and anything else is read as a gzip tarball.
+var archiveFormats = map[string]bool{"tar.gz": true, "tar.xz": true, "zip": true}
+
 func validArchiveURLTemplate(value string) bool {
 	if !strings.HasPrefix(value, "https://") ||
 		strings.Count(value, "{arch}")+strings.Count(value, "{gnuarch}") != 1 {
@@ -1502,8 +2037,8 @@ func validArchiveMember(value string) bool {
 // reconcileProjectEnvironment is the single provisioning path for new and
 // existing sprites. Every seven up repairs missing gstack/browser artifacts and
 // reruns Seven's typed pinned-tool reconciler.
-func reconcileProjectEnvironment(spriteName, repoDir, assistant string, opts upOptions) error {
-	manifest, manifestPresent, err := readProjectToolingManifest(spriteName, repoDir)
+func reconcileProjectEnvironment(ctx context.Context, spriteName, repoDir, assistant string, opts upOptions) error {
+	manifest, manifestPresent, err := readProjectToolingManifest(ctx, spriteName, repoDir)
 	if err != nil {
 		return err
 	}
@@ -1514,15 +2049,27 @@ func reconcileProjectEnvironment(spriteName, repoDir, assistant string, opts upO
 	if !required {
 		revision = gstackDefaultRevision
 	}
-	if err := maybeInstallGstack(spriteName, assistant, revision, reconcileOpts); err != nil {
+	if err := maybeInstallGstack(ctx, spriteName, assistant, revision, reconcileOpts); err != nil {
 		return fmt.Errorf("required gstack provisioning failed: %w", err)
 	}
-	if err := maybeInstallProjectTooling(spriteName, manifest, manifestPresent, opts); err != nil {
+	if err := maybeInstallProjectTooling(ctx, spriteName, manifest, manifestPresent, opts); err != nil {
 		return fmt.Errorf("required project tooling provisioning failed: %w", err)
 	}
 	return nil
 }
 
+// verifiedArchiveFetchFunc is the shell helper every pinned archive download
+// goes through: HTTPS only, and the file is kept only if it matches the exact
+// SHA-256 pinned for the Sprite's architecture.
+const verifiedArchiveFetchFunc = `fetch_verified_archive() {
+  fetch_url="$1" fetch_sha="$2" fetch_out="$3"
+  case "$fetch_url" in https://*) ;; *) return 1 ;; esac
+  case "$fetch_sha" in *[!0-9a-f]*) return 1 ;; esac
+  [ "${#fetch_sha}" -eq 64 ] || return 1
+  curl -fsSL "$fetch_url" -o "$fetch_out" &&
+    printf '%s  %s\n' "$fetch_sha" "$fetch_out" | sha256sum -c - >/dev/null 2>&1
+}`
+
 // projectToolingInstallScript builds Seven's typed manifest interpreter. It
 // deliberately supports a small fixed set of install mechanisms and never evals
 // repository text. All declared rows are required: drift or install failure
@@ -1535,7 +2082,18 @@ if command -v npm >/dev/null 2>&1; then
   NPM_BIN="$(npm prefix -g 2>/dev/null)/bin"
   case ":$PATH:" in *":$NPM_BIN:"*) ;; *) PATH="$NPM_BIN:$PATH"; export PATH ;; esac
 fi
-present="" installed="" failed=""
+if command -v go >/dev/null 2>&1; then
+  GO_BIN="$(go env GOBIN 2>/dev/null)"
+  [ -n "$GO_BIN" ] || { GO_PATH="$(go env GOPATH 2>/dev/null)"; GO_BIN="${GO_PATH%%:*}/bin"; }
+  case ":$PATH:" in *":$GO_BIN:"*) ;; *) PATH="$GO_BIN:$PATH"; export PATH ;; esac
+fi
+if command -v uv >/dev/null 2>&1; then
+  UV_BIN="$(uv tool dir --bin 2>/dev/null)"
+  case ":$PATH:" in *":$UV_BIN:"*) ;; *) [ -z "$UV_BIN" ] || { PATH="$UV_BIN:$PATH"; export PATH; } ;; esac
+fi
+CARGO_BIN="${CARGO_HOME:-$HOME/.cargo}/bin"
+case ":$PATH:" in *":$CARGO_BIN:"*) ;; *) PATH="$CARGO_BIN:$PATH"; export PATH ;; esac
+present="" installed="" failed="" apt_updated=""
 
 verify_pinned() {
   verify_name="$1" expected="$2" verify="$3"
@@ -1562,12 +2120,55 @@ verify_python_module() {
   python3 -c 'import importlib.metadata as m, importlib.util, pathlib, sys; d=m.distribution(sys.argv[1]); s=importlib.util.find_spec(sys.argv[2]); owned={pathlib.Path(d.locate_file(f)).resolve() for f in (d.files or [])}; origin=pathlib.Path(s.origin).resolve() if s and s.origin else None; providers=[p.lower() for p in m.packages_distributions().get(sys.argv[2], [])]; raise SystemExit(d.version != sys.argv[3] or d.metadata["Name"].lower() not in providers or origin not in owned)' "$module_dist" "$module_name" "$expected" >/dev/null 2>&1
 }
 
+` + verifiedArchiveFetchFunc + `
+
+extract_archive_member() {
+  extract_format="$1" extract_archive="$2" extract_member="$3" extract_out="$4"
+  case "$extract_format" in
+    tar.gz) extract_flag=z ;;
+    tar.xz) extract_flag=J ;;
+    zip)
+      command -v python3 >/dev/null 2>&1 || return 1
+      python3 -c '
+import stat, sys, zipfile
+archive = zipfile.ZipFile(sys.argv[1])
+matches = []
+for entry in archive.infolist():
+    mode = entry.external_attr >> 16
+    parts = entry.filename.split("/")
+    if entry.filename.startswith("/") or "\\" in entry.filename or ".." in parts or stat.S_ISLNK(mode):
+        raise SystemExit(1)
+    if entry.filename == sys.argv[2]:
+        matches.append(entry)
+if len(matches) != 1 or matches[0].is_dir():
+    raise SystemExit(1)
+mode = matches[0].external_attr >> 16
+if mode and not stat.S_ISREG(mode):
+    raise SystemExit(1)
+sys.stdout.buffer.write(archive.read(matches[0]))
+' "$extract_archive" "$extract_member" > "$extract_out" 2>/dev/null
+      return ;;
+    *) return 1 ;;
+  esac
+  extract_listing="$(tar -tv"$extract_flag"f "$extract_archive" -- "$extract_member" 2>/dev/null)" &&
+    [ "$(printf '%s\n' "$extract_listing" | wc -l | tr -d ' ')" = 1 ] &&
+    [ "${extract_listing#-}" != "$extract_listing" ] &&
+    tar -xO"$extract_flag"f "$extract_archive" -- "$extract_member" > "$extract_out" 2>/dev/null
+}
+
 install_archive() {
   archive_name="$1" archive_spec="$2"
   old_ifs="$IFS"; IFS='|'; set -f; set -- $archive_spec; set +f; IFS="$old_ifs"
-  [ "$#" -eq 6 ] || return 1
-  archive_version="$1" url_template="$2" sha_x86="$3" sha_arm="$4" archive_member="$5" archive_aliases="$6"
+  [ "$#" -eq 6 ] || [ "$#" -eq 7 ] || return 1
+  archive_version="$1" url_template="$2" sha_x86="$3" sha_arm="$4" archive_member="$5" archive_aliases="$6" archive_format="${7:-}"
   case "$url_template" in https://*) ;; *) return 1 ;; esac
+  if [ -z "$archive_format" ]; then
+    case "$url_template" in
+      *.zip) archive_format=zip ;;
+      *.tar.xz|*.txz) archive_format=tar.xz ;;
+      *) archive_format=tar.gz ;;
+    esac
+  fi
   case "$sha_x86$sha_arm" in *[!0-9a-f]*) return 1 ;; esac
   [ "${#sha_x86}" -eq 64 ] && [ "${#sha_arm}" -eq 64 ] || return 1
   case "$archive_member" in ''|/*|*//*|*[!A-Za-z0-9._/-]*) return 1 ;; esac
@@ -1584,12 +2185,8 @@ install_archive() {
   archive_url="$(printf '%s' "$url_template" | sed "s/{arch}/$archive_arch/g; s/{gnuarch}/$archive_gnuarch/g")"
   archive_tmp="$(mktemp -d)" || return 1
   archive_extracted="$archive_tmp/extracted"
-  if ! curl -fsSL "$archive_url" -o "$archive_tmp/archive.tgz" ||
-     ! printf '%s  %s\n' "$archive_sha" "$archive_tmp/archive.tgz" | sha256sum -c - >/dev/null 2>&1 ||
-     ! archive_listing="$(tar -tvzf "$archive_tmp/archive.tgz" -- "$archive_member" 2>/dev/null)" ||
-     [ "$(printf '%s\n' "$archive_listing" | wc -l | tr -d ' ')" != 1 ] ||
-     [ "${archive_listing#-}" = "$archive_listing" ] ||
-     ! tar -xOzf "$archive_tmp/archive.tgz" -- "$archive_member" > "$archive_extracted" 2>/dev/null ||
+  if ! fetch_verified_archive "$archive_url" "$archive_sha" "$archive_tmp/archive" ||
+     ! extract_archive_member "$archive_format" "$archive_tmp/archive" "$archive_member" "$archive_extracted" ||
      [ ! -f "$archive_extracted" ]; then
     rm -rf "$archive_tmp"; return 1
   fi
@@ -1614,6 +2211,52 @@ install_archive() {
   return 0
 }
 
+run_verifier() {
+  old_ifs="$IFS"; IFS=' '; set -f; set -- $1; set +f; IFS="$old_ifs"
+  [ "$#" -eq 2 ] || return 1
+  case "$2" in --version|version) ;; *) return 1 ;; esac
+  verify_path="$(command -v "$1" 2>/dev/null)" || return 1
+  case "$verify_path" in /*) ;; *) return 1 ;; esac
+  "$verify_path" "$2" >/dev/null 2>&1 </dev/null
+}
+
+verify_apt() {
+  apt_package="$1" expected="$2" verify="$3"
+  command -v dpkg-query >/dev/null 2>&1 || return 1
+  [ "$(dpkg-query -W -f='${Status} ${Version}' "$apt_package" 2>/dev/null)" = "install ok installed $expected" ] || return 1
+  run_verifier "$verify"
+}
+
+install_apt() {
+  command -v apt-get >/dev/null 2>&1 || return 1
+  if [ "$(id -u)" = 0 ]; then apt_sudo=""
+  elif command -v sudo >/dev/null 2>&1; then apt_sudo="sudo -n"
+  else return 1; fi
+  if [ -z "$apt_updated" ]; then
+    $apt_sudo env DEBIAN_FRONTEND=noninteractive apt-get update -qq >/dev/null 2>&1 </dev/null || return 1
+    apt_updated=1
+  fi
+  $apt_sudo env DEBIAN_FRONTEND=noninteractive apt-get install -y -qq --no-install-recommends --allow-downgrades "$1" >/dev/null 2>&1 </dev/null
+}
+
+verify_go() {
+  go_name="$1" go_package="$2" expected="$3" verify="$4"
+  case "$verify" in "$go_name --version"|"$go_name version") ;; *) return 1 ;; esac
+  command -v go >/dev/null 2>&1 || return 1
+  go_path="$(command -v "$go_name" 2>/dev/null)" || return 1
+  case "$go_path" in /*) ;; *) return 1 ;; esac
+  go version -m "$go_path" 2>/dev/null |
+    awk -F '\t' -v pkg="$go_package" -v version="$expected" '$2 == "path" && $3 == pkg { p = 1 } $2 == "mod" && $4 == version { m = 1 } END { exit !(p && m) }' || return 1
+  run_verifier "$verify"
+}
+
+verify_cargo() {
+  cargo_crate="$1" expected="$2" verify="$3"
+  command -v cargo >/dev/null 2>&1 || return 1
+  cargo install --list 2>/dev/null | grep -qxF "$cargo_crate v$expected:" || return 1
+  run_verifier "$verify"
+}
+
 while read -r kind name spec verify || [ -n "$kind$name$spec$verify" ]; do
   case "$kind" in ''|\#*) continue ;; esac
   [ "$kind" = gstack ] && continue
@@ -1633,6 +2276,16 @@ while read -r kind name spec verify || [ -n "$kind$name$spec$verify" ]; do
       elif command -v python3 >/dev/null 2>&1 && python3 -m pip install --user -- "$spec" >/dev/null 2>&1 && verify_pinned "$name" "$expected" "$verify"; then installed="$installed $name"
       else failed="$failed $name"; fi
       ;;
+    uv-tool)
+      case "$spec" in "$name"==[0-9]*.[0-9]*.[0-9]*) expected="${spec##*==}" ;; *) failed="$failed $name"; continue ;; esac
+      case "$expected" in *[!0-9.]*|.*|*.|*.*.*.*) failed="$failed $name"; continue ;; esac
+      if verify_pinned "$name" "$expected" "$verify"; then present="$present $name"
+      elif ! command -v uv >/dev/null 2>&1; then
+        echo "[project-tooling] $name: uv is not installed in the sprite (uv-tool rows never fall back to pip)"
+        failed="$failed $name"
+      elif uv tool install --force -- "$spec" >/dev/null 2>&1 </dev/null && verify_pinned "$name" "$expected" "$verify"; then installed="$installed $name"
+      else failed="$failed $name"; fi
+      ;;
     pip-module)
       expected="${spec##*==}"
       old_ifs="$IFS"; IFS=' '; set -f; set -- $verify; set +f; IFS="$old_ifs"
@@ -1647,6 +2300,28 @@ while read -r kind name spec verify || [ -n "$kind$name$spec$verify" ]; do
       elif install_archive "$name" "$spec" && verify_pinned "$name" "$expected" "$verify"; then installed="$installed $name"
       else failed="$failed $name"; fi
       ;;
+    apt)
+      case "$spec" in "$name"=[0-9]*) expected="${spec#"$name"=}" ;; *) failed="$failed $name"; continue ;; esac
+      case "$expected" in *[!A-Za-z0-9.+~:-]*) failed="$failed $name"; continue ;; esac
+      if verify_apt "$name" "$expected" "$verify"; then present="$present $name"
+      elif install_apt "$spec" && verify_apt "$name" "$expected" "$verify"; then installed="$installed $name"
+      else failed="$failed $name"; fi
+      ;;
+    go)
+      case "$spec" in *@v[0-9]*.[0-9]*.[0-9]*) expected="${spec##*@}"; go_package="${spec%@*}" ;; *) failed="$failed $name"; continue ;; esac
+      case "$expected" in v*[!0-9.]*|v.*|*.|v*.*.*.*) failed="$failed $name"; continue ;; esac
+      case "$go_package" in ''|*[!A-Za-z0-9._~/-]*) failed="$failed $name"; continue ;; esac
+      if verify_go "$name" "$go_package" "$expected" "$verify"; then present="$present $name"
+      elif command -v go >/dev/null 2>&1 && go install "$spec" >/dev/null 2>&1 </dev/null && verify_go "$name" "$go_package" "$expected" "$verify"; then installed="$installed $name"
+      else failed="$failed $name"; fi
+      ;;
+    cargo)
+      case "$spec" in "$name"@[0-9]*.[0-9]*.[0-9]*) expected="${spec##*@}" ;; *) failed="$failed $name"; continue ;; esac
+      case "$expected" in *[!0-9.]*|.*|*.|*.*.*.*) failed="$failed $name"; continue ;; esac
+      if verify_cargo "$name" "$expected" "$verify"; then present="$present $name"
+      elif command -v cargo >/dev/null 2>&1 && cargo install --locked --version "$expected" "$name" >/dev/null 2>&1 </dev/null && verify_cargo "$name" "$expected" "$verify"; then installed="$installed $name"
+      else failed="$failed $name"; fi
+      ;;
     *) failed="$failed $name" ;;
   esac
 done <<'SEVEN_TOOLING_MANIFEST'
@@ -1659,12 +2334,12 @@ echo "[project-tooling] present:${present:- none} | installed:${installed:- none
 // maybeInstallProjectTooling reconciles a repo's declared CLI/MCP tooling using
 // Seven's typed interpreter. A missing manifest is the common no-op; a present
 // manifest is a required contract and failures propagate to the caller.
-func maybeInstallProjectTooling(spriteName string, manifest validatedToolingManifest, manifestPresent bool, opts upOptions) error {
+func maybeInstallProjectTooling(ctx context.Context, spriteName string, manifest validatedToolingManifest, manifestPresent bool, opts upOptions) error {
 	if !manifestPresent {
 		return nil
 	}
 	opts.Logger("[seven up] project tooling manifest found — reconciling pinned tools")
-	out, err := spriteExecOutput(spriteName, nil, "sh", "-lc", projectToolingInstallScript(manifest.normalized()))
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", projectToolingInstallScript(manifest.normalized()))
 	if err != nil {
 		return fmt.Errorf("project tooling install failed: %w%s", err, gstackOutputTail(out))
 	}
@@ -1678,16 +2353,16 @@ func maybeInstallProjectTooling(spriteName string, manifest validatedToolingMani
 // one-line banner inside the sprite so it is obvious which sprite a console
 // belongs to. The color is derived from the sprite name (see spriteColor) and is
 // stable across sessions, so sibling sprites stay visually distinct. It also
-// defines assistant aliases for full-permission Claude and Codex sessions (safe
-// in a disposable sandbox). Snippets are written for bash, zsh, and fish and
-// sourced from the usual rc files.
-func configureSpriteIdentity(spriteName string, opts upOptions) error {
+// defines assistant aliases for full-permission Claude, Codex, Cursor, and
+// Gemini sessions (safe in a disposable sandbox). Snippets are written for
+// bash, zsh, and fish and sourced from the usual rc files.
+func configureSpriteIdentity(ctx context.Context, spriteName string, opts upOptions) error {
 	if spriteName == "" {
 		return nil
 	}
 	color := spriteColor(spriteName)
 	opts.Logger(fmt.Sprintf("[seven init] configuring sprite identity prompt: %s", spriteName))
-	env := []string{"SEVEN_SPRITE_NAME=" + spriteName + ",SEVEN_SPRITE_COLOR=" + color}
+	env := []string{"SEVEN_SPRITE_NAME=" + spriteName, "SEVEN_SPRITE_COLOR=" + color}
 	idPath := sevenSpriteIdentityPath
 	cmd := `set -e
 {
@@ -1700,6 +2375,8 @@ case "$-" in
     # Sprites are disposable sandboxes, so run assistants with full permissions.
     alias c='claude --dangerously-skip-permissions'
     alias c2='codex --dangerously-bypass-approvals-and-sandbox'
+    alias c3='cursor-agent --force'
+    alias c4='gemini --yolo'
     if [ -z "${SEVEN_SPRITE_PROMPT_SET:-}" ]; then
       SEVEN_SPRITE_PROMPT_SET=1
       __seven_c="${SEVEN_SPRITE_COLOR:-7}"
@@ -1726,6 +2403,8 @@ status is-interactive; or exit 0
 # Sprites are disposable sandboxes, so run assistants with full permissions.
 alias c 'claude --dangerously-skip-permissions'
 alias c2 'codex --dangerously-bypass-approvals-and-sandbox'
+alias c3 'cursor-agent --force'
+alias c4 'gemini --yolo'
 if not functions -q __seven_orig_fish_prompt
   if functions -q fish_prompt
     functions -c fish_prompt __seven_orig_fish_prompt
@@ -1750,19 +2429,20 @@ for rc in "$HOME/.bash_profile" "$HOME/.profile" "$HOME/.bashrc" "$HOME/.zshrc"
   grep -Fqx '[ -f "` + idPath + `" ] && . "` + idPath + `"' "$rc" || printf '\n%s\n' '[ -f "` + idPath + `" ] && . "` + idPath + `"' >> "$rc"
 done
 `
-	return spriteExec(spriteName, env, opts.QuietExternal, "sh", "-lc", cmd)
+	return spriteExec(ctx, spriteName, env, opts.QuietExternal, "sh", "-lc", cmd)
 }
 
-func spriteList() (string, error) {
-	out, err := runCmdOutput(spriteBin(), nil, "list")
+// spriteList returns the backend's sandbox names, one per line.
+func spriteList(ctx context.Context) (string, error) {
+	names, err := activeBackend.List(ctx)
 	if err != nil {
 		return "", err
 	}
-	return out, nil
+	return strings.Join(names, "\n"), nil
 }
 
-func spriteExists(name string) (bool, error) {
-	out, err := spriteList()
+func spriteExists(ctx context.Context, name string) (bool, error) {
+	out, err := spriteList(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -1794,19 +2474,19 @@ func spriteListedInOutput(out, name string) bool {
 // detectRepoCheckout returns a clean branch + commit identity. A dirty or
 // detached checkout cannot be reproduced by cloning and therefore must never
 // be used as evidence from a supposedly clean Sprite.
-func detectRepoCheckout(opts upOptions) (string, string, error) {
+func detectRepoCheckout(ctx context.Context, opts upOptions) (string, string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return "", "", err
 	}
-	dirty, err := runCmdOutput("git", nil, "-C", cwd, "status", "--porcelain", "--untracked-files=normal", "--", ".", ":(exclude).sprite")
+	dirty, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "status", "--porcelain", "--untracked-files=normal", "--", ".", ":(exclude).sprite")
 	if err != nil {
 		return "", "", fmt.Errorf("inspect host checkout: %w", err)
 	}
 	if strings.TrimSpace(dirty) != "" {
 		return "", "", fmt.Errorf("host checkout is dirty; commit and push it before creating a reproducible Sprite")
 	}
-	branch, err := runCmdOutput("git", nil, "-C", cwd, "symbolic-ref", "--quiet", "--short", "HEAD")
+	branch, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "symbolic-ref", "--quiet", "--short", "HEAD")
 	if err != nil {
 		return "", "", fmt.Errorf("host checkout is detached; use a pushed branch before creating a reproducible Sprite")
 	}
@@ -1814,11 +2494,11 @@ func detectRepoCheckout(opts upOptions) (string, string, error) {
 	if branch == "" {
 		return "", "", fmt.Errorf("host checkout has no branch")
 	}
-	if _, err := runCmdOutput("git", nil, "check-ref-format", "--branch", branch); err != nil {
+	if _, err := runCmdOutput(ctx, "git", nil, "check-ref-format", "--branch", branch); err != nil {
 		opts.Logger(fmt.Sprintf("[seven init] ignoring invalid host branch %q", branch))
 		return "", "", fmt.Errorf("invalid host branch %q", branch)
 	}
-	head, err := runCmdOutput("git", nil, "-C", cwd, "rev-parse", "HEAD")
+	head, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "rev-parse", "HEAD")
 	if err != nil {
 		return "", "", fmt.Errorf("resolve host HEAD: %w", err)
 	}
@@ -1829,18 +2509,18 @@ func detectRepoCheckout(opts upOptions) (string, string, error) {
 	return branch, head, nil
 }
 
-func verifyClonedRepoHead(spriteName, repoDir, expectedHead string) error {
+func verifyClonedRepoHead(ctx context.Context, spriteName, repoDir, expectedHead string) error {
 	if expectedHead == "" {
 		return nil
 	}
 	cmd := `[ "$(git -C "$HOME/` + repoDir + `" rev-parse HEAD)" = "` + expectedHead + `" ]`
-	if err := spriteExec(spriteName, nil, true, "sh", "-lc", cmd); err != nil {
+	if err := spriteExec(ctx, spriteName, nil, true, "sh", "-lc", cmd); err != nil {
 		return fmt.Errorf("cloned Sprite HEAD does not match host HEAD %s; push the branch and retry", expectedHead)
 	}
 	return nil
 }
 
-func detectRepoInfo(spriteName string, opts upOptions) (string, string, string, error) {
+func detectRepoInfo(ctx context.Context, spriteName string, opts upOptions) (string, string, string, error) {
 	if _, err := exec.LookPath("git"); err != nil {
 		opts.Logger("[seven init] git not found")
 		return "", "", "", nil
@@ -1851,13 +2531,13 @@ func detectRepoInfo(spriteName string, opts upOptions) (string, string, string,
 		return "", "", "", err
 	}
 
-	inside, err := runCmdOutput("git", nil, "-C", cwd, "rev-parse", "--is-inside-work-tree")
+	inside, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "rev-parse", "--is-inside-work-tree")
 	if err != nil || strings.TrimSpace(inside) != "true" {
 		opts.Logger("[seven init] not inside a git repo")
 		return "", "", "", nil
 	}
 
-	remotes, err := runCmdOutput("git", nil, "-C", cwd, "remote")
+	remotes, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "remote")
 	if err != nil {
 		return "", "", "", err
 	}
@@ -1870,7 +2550,7 @@ func detectRepoInfo(spriteName string, opts upOptions) (string, string, string,
 		return "", "", "", nil
 	}
 
-	repoURL, err := runCmdOutput("git", nil, "-C", cwd, "remote", "get-url", "origin")
+	repoURL, err := runCmdOutput(ctx, "git", nil, "-C", cwd, "remote", "get-url", "origin")
 	if err != nil {
 		return "", "", "", err
 	}
@@ -1882,14 +2562,7 @@ func detectRepoInfo(spriteName string, opts upOptions) (string, string, string,
 	opts.Logger(fmt.Sprintf("[seven init] repo url: %s", repoURL))
 
 	repoSlug := githubRepoSlug(repoURL)
-	ghToken := ""
-	if _, err := exec.LookPath("gh"); err == nil {
-		token, err := runCmdOutput("gh", nil, "auth", "token")
-		if err == nil {
-			ghToken = strings.TrimSpace(token)
-		}
-	}
-
+	ghToken := hostGhToken(ctx)
 	if ghToken != "" {
 		opts.Logger("[seven init] detected gh token on host")
 	}
@@ -1897,25 +2570,38 @@ func detectRepoInfo(spriteName string, opts upOptions) (string, string, string,
 	return repoURL, repoSlug, ghToken, nil
 }
 
-func ensureGhAuthInSprite(spriteName, ghToken string, opts upOptions) error {
+// hostGhToken returns the host gh CLI's token, or "" when gh is missing or
+// logged out.
+func hostGhToken(ctx context.Context) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+	token, err := runCmdOutput(ctx, "gh", nil, "auth", "token")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func ensureGhAuthInSprite(ctx context.Context, spriteName, ghToken, phase string, opts upOptions) error {
 	if ghToken == "" {
 		return nil
 	}
 
-	opts.Logger("[seven init] configuring gh auth inside sprite")
+	opts.Logger(fmt.Sprintf("%s configuring gh auth inside sprite", phase))
 	env := []string{"GH_TOKEN=" + ghToken}
-	if err := spriteExec(spriteName, env, opts.QuietExternal, "sh", "-lc", "command -v gh >/dev/null 2>&1"); err != nil {
+	if err := spriteExec(ctx, spriteName, env, opts.QuietExternal, "sh", "-lc", "command -v gh >/dev/null 2>&1"); err != nil {
 		return fmt.Errorf("gh not found in sprite: %w", err)
 	}
 	loginCmd := "token=\"$GH_TOKEN\"; unset GH_TOKEN; printf '%s' \"$token\" | gh auth login --with-token -h github.com"
-	if out, err := spriteExecOutput(spriteName, env, "sh", "-lc", loginCmd); err != nil {
+	if out, err := spriteExecOutput(ctx, spriteName, env, "sh", "-lc", loginCmd); err != nil {
 		msg := strings.TrimSpace(out)
 		if msg != "" {
 			return fmt.Errorf("gh auth login failed: %w (%s)", err, msg)
 		}
 		return fmt.Errorf("gh auth login failed: %w", err)
 	}
-	if out, err := spriteExecOutput(spriteName, env, "gh", "auth", "setup-git"); err != nil {
+	if out, err := spriteExecOutput(ctx, spriteName, env, "gh", "auth", "setup-git"); err != nil {
 		msg := strings.TrimSpace(out)
 		if msg != "" {
 			return fmt.Errorf("gh auth setup-git failed: %w (%s)", err, msg)
@@ -1925,15 +2611,19 @@ func ensureGhAuthInSprite(spriteName, ghToken string, opts upOptions) error {
 	return nil
 }
 
-func detectHostAssistantState(opts upOptions) hostAssistantState {
+func detectHostAssistantState(ctx context.Context, opts upOptions) hostAssistantState {
 	state := hostAssistantState{
 		PreferredAssistant: sevenDefaultAssistant,
 	}
-	state.ClaudeAuthPath = detectHostClaudeAuth(opts)
+	state.ClaudeAuthPath = detectHostClaudeAuth(ctx, opts)
 	state.ClaudeConfigPath = detectHostClaudeConfig(opts)
 	state.ClaudeCredentials = detectHostClaudeCredentials(opts)
-	state.CodexAuthPath = detectHostCodexChatGPTAuth(opts)
+	state.CodexAuthPath = detectHostCodexChatGPTAuth(ctx, opts)
 	state.CodexConfigPath = detectHostCodexConfig(opts)
+	state.CursorAuthPath = detectHostCursorAuth(opts)
+	state.CursorConfigPath = detectHostCursorConfig(opts)
+	state.GeminiAuthPath = detectHostGeminiAuth(opts)
+	state.GeminiConfigPath = detectHostGeminiConfig(opts)
 	if opts.Assistant != "" {
 		state.PreferredAssistant = opts.Assistant
 		return state
@@ -1944,87 +2634,140 @@ func detectHostAssistantState(opts upOptions) hostAssistantState {
 		state.PreferredAssistant = "claude"
 	case state.CodexAuthPath != "":
 		state.PreferredAssistant = "codex"
+	case state.CursorAuthPath != "":
+		state.PreferredAssistant = "cursor"
+	case state.GeminiAuthPath != "":
+		state.PreferredAssistant = "gemini"
 	}
 
 	return state
 }
 
-func syncHostAssistantState(spriteName string, state hostAssistantState, phase string, opts upOptions) hostAssistantState {
-	if err := ensureClaudeConfigInSprite(spriteName, state.ClaudeConfigPath, opts); err != nil {
-		opts.Logger(fmt.Sprintf("%s claude config setup failed: %v", phase, err))
-	}
-	if err := ensureClaudeAuthInSprite(spriteName, state.ClaudeAuthPath, opts); err != nil {
-		opts.Logger(fmt.Sprintf("%s claude auth setup failed: %v", phase, err))
-	}
-	if err := ensureClaudeCredentialsInSprite(spriteName, state.ClaudeCredentials, opts); err != nil {
-		opts.Logger(fmt.Sprintf("%s claude credentials setup failed: %v", phase, err))
-	}
-	if err := ensureCodexConfigInSprite(spriteName, state.CodexConfigPath, opts); err != nil {
-		opts.Logger(fmt.Sprintf("%s codex config setup failed: %v", phase, err))
-	}
-	if err := ensureCodexAuthInSprite(spriteName, state.CodexAuthPath, opts); err != nil {
-		opts.Logger(fmt.Sprintf("%s codex auth setup failed: %v", phase, err))
+func syncHostAssistantState(ctx context.Context, spriteName string, state hostAssistantState, phase string, opts upOptions) hostAssistantState {
+	state = skipExcludedCredentialSync(state, phase, opts)
+	files, cleanup := hostAssistantSyncFiles(state, phase, opts)
+	defer cleanup()
+	// A failed sync leaves the sprite usable: the assistant can be logged in
+	// to by hand, so it is reported rather than returned.
+	if _, err := syncHostFilesToSprite(ctx, spriteName, files, phase, opts); err != nil {
+		opts.Logger(fmt.Sprintf("%s assistant file sync failed: %v", phase, err))
 	}
+	state.PreferredAssistant = resolvePreferredAssistantInSprite(ctx, spriteName, state, phase, opts)
+	return state
+}
 
-	state.PreferredAssistant = resolvePreferredAssistantInSprite(spriteName, state, phase, opts)
+// skipExcludedCredentialSync clears the host paths of assistants the user
+// profile keeps out of the sprite, so nothing of theirs is synced, and of the
+// Claude credentials when the sprite has a login of its own.
+func skipExcludedCredentialSync(state hostAssistantState, phase string, opts upOptions) hostAssistantState {
+	for _, name := range opts.SkipCredentialSync {
+		opts.Logger(fmt.Sprintf("%s not syncing %s credentials (excluded by the user profile)", phase, name))
+		switch name {
+		case "claude":
+			state.ClaudeConfigPath, state.ClaudeAuthPath = "", ""
+			state.ClaudeCredentials = claudeCredentialsSource{}
+		case "codex":
+			state.CodexConfigPath, state.CodexAuthPath = "", ""
+		case "cursor":
+			state.CursorConfigPath, state.CursorAuthPath = "", ""
+		case "gemini":
+			state.GeminiConfigPath, state.GeminiAuthPath = "", ""
+		}
+	}
+	if opts.ClaudeOwnLogin && (state.ClaudeAuthPath != "" || state.ClaudeCredentials.present()) {
+		opts.Logger(fmt.Sprintf("%s not syncing claude credentials (the sprite logs in on its own)", phase))
+		state.ClaudeAuthPath = ""
+		state.ClaudeCredentials = claudeCredentialsSource{}
+	}
 	return state
 }
 
-func resolvePreferredAssistantInSprite(spriteName string, state hostAssistantState, phase string, opts upOptions) string {
+func resolvePreferredAssistantInSprite(ctx context.Context, spriteName string, state hostAssistantState, phase string, opts upOptions) string {
 	if opts.Assistant != "" {
 		return opts.Assistant
 	}
-	if loggedIn, err := spriteClaudeLoggedIn(spriteName); err != nil {
+	if status, err := spriteClaudeAuthStatus(ctx, spriteName); err != nil {
 		opts.Logger(fmt.Sprintf("%s claude auth validation failed: %v", phase, err))
-	} else if loggedIn {
+	} else if status.LoggedIn {
+		return "claude"
+	} else if status.TokenReused {
+		opts.Logger(fmt.Sprintf("%s claude's refresh token in the sprite was already used by another copy (the host or a sibling sprite); run 'seven up --claude-login' to give the sprite its own login. Falling back to %s", phase, sevenDefaultAssistant))
+	} else if opts.ClaudeOwnLogin {
+		// seven up and seven init log claude in once the sprite is ready.
 		return "claude"
 	} else if state.ClaudeAuthPath != "" || state.ClaudeCredentials.present() {
 		opts.Logger(fmt.Sprintf("%s claude auth is not usable in sprite; run 'claude' inside the sprite to log in (or 'codex login'), then retry. Falling back to %s", phase, sevenDefaultAssistant))
 	}
 
-	if state.CodexAuthPath != "" {
+	switch {
+	case state.CodexAuthPath != "":
 		return "codex"
+	case state.CursorAuthPath != "":
+		return "cursor"
+	case state.GeminiAuthPath != "":
+		return "gemini"
 	}
 
 	return sevenDefaultAssistant
 }
 
-func spriteClaudeLoggedIn(spriteName string) (bool, error) {
-	if err := spriteExec(spriteName, nil, true, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
-		return false, nil
+// spriteClaudeAuthStatus asks the sprite's claude whether it is logged in. A
+// sprite without claude reports the zero status.
+func spriteClaudeAuthStatus(ctx context.Context, spriteName string) (claudeAuthStatus, error) {
+	if err := spriteExec(ctx, spriteName, nil, true, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
+		return claudeAuthStatus{}, nil
 	}
 
-	status, err := spriteExecOutput(spriteName, nil, "claude", "auth", "status", "--json")
-	if loggedIn, ok := parseClaudeAuthStatus(status); ok {
-		return loggedIn, nil
+	out, err := spriteExecOutput(ctx, spriteName, nil, "claude", "auth", "status", "--json")
+	if status, ok := parseClaudeAuthStatus(out); ok {
+		return status, nil
 	}
 	if err != nil {
-		return false, err
+		return claudeAuthStatus{}, err
 	}
-	return false, nil
+	return claudeAuthStatus{}, nil
 }
 
-func parseClaudeAuthStatus(status string) (bool, bool) {
+// claudeAuthStatus is what `claude auth status --json` says about a login.
+type claudeAuthStatus struct {
+	LoggedIn bool
+	// TokenReused means the refresh token was already spent by another copy
+	// of the same credentials (the host or a sibling sprite), so this copy
+	// cannot refresh any more and needs a login of its own.
+	TokenReused bool
+}
+
+// claudeTokenReusedMessage is how claude reports a refresh token that another
+// copy of the credentials has already exchanged.
+const claudeTokenReusedMessage = "token has already been used"
+
+// parseClaudeAuthStatus reads `claude auth status --json`. The refresh error
+// can arrive in the JSON or as text beside it, so the whole output is searched
+// for it first.
+func parseClaudeAuthStatus(out string) (claudeAuthStatus, bool) {
+	if strings.Contains(strings.ToLower(out), claudeTokenReusedMessage) {
+		return claudeAuthStatus{TokenReused: true}, true
+	}
 	var parsed struct {
 		LoggedIn bool `json:"loggedIn"`
 	}
-	if err := json.Unmarshal([]byte(status), &parsed); err != nil {
-		return false, false
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		return claudeAuthStatus{}, false
 	}
-	return parsed.LoggedIn, true
+	return claudeAuthStatus{LoggedIn: parsed.LoggedIn}, true
 }
 
-func detectHostClaudeAuth(opts upOptions) string {
+func detectHostClaudeAuth(ctx context.Context, opts upOptions) string {
 	if _, err := exec.LookPath("claude"); err != nil {
 		return ""
 	}
 
-	status, err := runCmdOutput("claude", nil, "auth", "status", "--json")
+	status, err := runCmdOutput(ctx, "claude", nil, "auth", "status", "--json")
 	if err != nil && strings.TrimSpace(status) == "" {
 		return ""
 	}
-	loggedIn, ok := parseClaudeAuthStatus(status)
-	if !ok || !loggedIn {
+	parsed, ok := parseClaudeAuthStatus(status)
+	if !ok || !parsed.LoggedIn {
 		return ""
 	}
 
@@ -2115,12 +2858,12 @@ func extractClaudeKeychainCredentials() (string, error) {
 	return "", lastErr
 }
 
-func detectHostCodexChatGPTAuth(opts upOptions) string {
+func detectHostCodexChatGPTAuth(ctx context.Context, opts upOptions) string {
 	if _, err := exec.LookPath("codex"); err != nil {
 		return ""
 	}
 
-	status, err := runCmdOutput("codex", nil, "login", "status")
+	status, err := runCmdOutput(ctx, "codex", nil, "login", "status")
 	if err != nil {
 		return ""
 	}
@@ -2157,268 +2900,106 @@ func detectHostCodexConfig(opts upOptions) string {
 	return configPath
 }
 
-// deepMergeJSON merges src into dst recursively. For nested maps both sides
-// are recursed; for everything else src wins. dst is mutated and returned.
-func deepMergeJSON(dst, src map[string]interface{}) map[string]interface{} {
-	for k, srcVal := range src {
-		if dstVal, exists := dst[k]; exists {
-			srcMap, srcIsMap := srcVal.(map[string]interface{})
-			dstMap, dstIsMap := dstVal.(map[string]interface{})
-			if srcIsMap && dstIsMap {
-				dst[k] = deepMergeJSON(dstMap, srcMap)
-				continue
-			}
-		}
-		dst[k] = srcVal
+// detectHostCursorAuth finds the Cursor CLI's login on Linux, where
+// cursor-agent keeps its tokens in ~/.config/cursor/auth.json. On macOS they
+// live in the Keychain, so there is nothing to copy.
+func detectHostCursorAuth(opts upOptions) string {
+	authPath := hostHomeFile(".config", "cursor", "auth.json")
+	if authPath == "" {
+		return ""
 	}
-	return dst
+	opts.Logger("[seven init] detected host cursor-agent auth")
+	return authPath
 }
 
-// mergedJSONForSprite reads the host JSON file and the sprite's existing copy
-// (via spriteReadCmd), deep-merges host values into the sprite's version so
-// that sprite-only keys are preserved, and returns the path to the file that
-// should be copied into the sprite. When there is no existing sprite file or
-// the merge cannot be performed it returns the original hostPath unchanged.
-// If a temporary file is created the returned cleanup func removes it.
-func mergedJSONForSprite(spriteName, hostPath, spriteReadCmd string) (path string, cleanup func(), err error) {
-	hostData, err := os.ReadFile(hostPath)
-	if err != nil {
-		return "", nil, fmt.Errorf("reading host config: %w", err)
-	}
-	var hostJSON map[string]interface{}
-	if err := json.Unmarshal(hostData, &hostJSON); err != nil {
-		return hostPath, nil, nil
-	}
-
-	spriteData, readErr := spriteExecOutput(spriteName, nil, "sh", "-lc", spriteReadCmd)
-	if readErr != nil || strings.TrimSpace(spriteData) == "" {
-		return hostPath, nil, nil
-	}
-
-	var spriteJSON map[string]interface{}
-	if err := json.Unmarshal([]byte(spriteData), &spriteJSON); err != nil {
-		return hostPath, nil, nil
-	}
-
-	merged := deepMergeJSON(spriteJSON, hostJSON)
-
-	data, err := json.MarshalIndent(merged, "", "  ")
-	if err != nil {
-		return hostPath, nil, nil
-	}
-
-	tmpFile, err := os.CreateTemp("", "seven-merged-*.json")
-	if err != nil {
-		return hostPath, nil, nil
-	}
-	if _, writeErr := tmpFile.Write(data); writeErr != nil {
-		tmpFile.Close()
-		os.Remove(tmpFile.Name())
-		return hostPath, nil, nil
+func detectHostCursorConfig(opts upOptions) string {
+	configPath := hostHomeFile(".cursor", "cli-config.json")
+	if configPath == "" {
+		return ""
 	}
-	tmpFile.Close()
-
-	return tmpFile.Name(), func() { os.Remove(tmpFile.Name()) }, nil
+	opts.Logger("[seven init] detected host cursor-agent config")
+	return configPath
 }
 
-func ensureClaudeConfigInSprite(spriteName, hostConfigPath string, opts upOptions) error {
-	if hostConfigPath == "" {
-		return nil
-	}
-	if err := spriteExec(spriteName, nil, opts.QuietExternal, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
-		opts.Logger("[seven init] claude not found in sprite, skipping claude config sync")
-		return nil
-	}
-
-	opts.Logger("[seven init] syncing claude config into sprite")
-
-	srcPath, cleanup, err := mergedJSONForSprite(spriteName, hostConfigPath, `cat "$HOME/.claude/settings.json" 2>/dev/null`)
-	if err != nil {
-		return err
-	}
-	if cleanup != nil {
-		defer cleanup()
-	}
-
-	copySpec := srcPath + ":/tmp/host-claude-settings.json"
-	cmdArgs := []string{
-		"exec",
-		"-s", spriteName,
-		"-file", copySpec,
-		"--",
-		"sh", "-lc", "install -d -m 700 \"$HOME/.claude\" && install -m 600 /tmp/host-claude-settings.json \"$HOME/.claude/settings.json\" && rm -f /tmp/host-claude-settings.json",
-	}
-	if opts.QuietExternal {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
+// detectHostGeminiAuth finds the Gemini CLI's cached Google login. API-key
+// logins live in the environment, which seven does not copy.
+func detectHostGeminiAuth(opts upOptions) string {
+	authPath := hostHomeFile(".gemini", "oauth_creds.json")
+	if authPath == "" {
+		return ""
 	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
+	opts.Logger("[seven init] detected host gemini auth")
+	return authPath
 }
 
-func ensureClaudeAuthInSprite(spriteName, hostAuthPath string, opts upOptions) error {
-	if hostAuthPath == "" {
-		return nil
-	}
-	if err := spriteExec(spriteName, nil, opts.QuietExternal, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
-		opts.Logger("[seven init] claude not found in sprite, skipping claude auth sync")
-		return nil
+func detectHostGeminiConfig(opts upOptions) string {
+	configPath := hostHomeFile(".gemini", "settings.json")
+	if configPath == "" {
+		return ""
 	}
+	opts.Logger("[seven init] detected host gemini config")
+	return configPath
+}
 
-	opts.Logger("[seven init] syncing claude auth into sprite")
-
-	srcPath, cleanup, err := mergedJSONForSprite(spriteName, hostAuthPath, `cat "$HOME/.claude.json" 2>/dev/null`)
+// hostHomeFile returns the path of a non-empty regular file under the host's
+// home directory, or "" when there is none.
+func hostHomeFile(elem ...string) string {
+	home, err := os.UserHomeDir()
 	if err != nil {
-		return err
-	}
-	if cleanup != nil {
-		defer cleanup()
-	}
-
-	copySpec := srcPath + ":/tmp/host-claude-auth.json"
-	cmdArgs := []string{
-		"exec",
-		"-s", spriteName,
-		"-file", copySpec,
-		"--",
-		"sh", "-lc", "install -m 600 /tmp/host-claude-auth.json \"$HOME/.claude.json\" && rm -f /tmp/host-claude-auth.json",
+		return ""
 	}
-	if opts.QuietExternal {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
+	path := filepath.Join(append([]string{home}, elem...)...)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Size() == 0 {
+		return ""
 	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
+	return path
 }
 
-// ensureClaudeCredentialsInSprite copies the host's Claude Code OAuth credential
-// store into the sprite's ~/.claude/.credentials.json. Unlike the config/auth
-// syncs this overwrites rather than merges: the host token is the freshest copy,
-// and we want it to replace any stale token already in the sprite. The source is
-// either a host file (Linux) or extracted from the macOS Keychain.
-func ensureClaudeCredentialsInSprite(spriteName string, src claudeCredentialsSource, opts upOptions) error {
-	if !src.present() {
-		return nil
-	}
-	if err := spriteExec(spriteName, nil, opts.QuietExternal, "sh", "-lc", "command -v claude >/dev/null 2>&1"); err != nil {
-		opts.Logger("[seven init] claude not found in sprite, skipping claude credentials sync")
-		return nil
-	}
-
-	hostPath := src.FilePath
-	if src.Keychain {
-		data, err := extractClaudeKeychainCredentials()
-		if err != nil {
-			return fmt.Errorf("reading claude credentials from keychain: %w", err)
-		}
-		tmpFile, err := os.CreateTemp("", "seven-claude-credentials-*.json")
-		if err != nil {
-			return err
-		}
-		defer os.Remove(tmpFile.Name())
-		if _, err := tmpFile.WriteString(data); err != nil {
-			tmpFile.Close()
-			return err
-		}
-		if err := tmpFile.Chmod(0o600); err != nil {
-			tmpFile.Close()
-			return err
+// deepMergeJSON merges src into dst recursively. For nested maps both sides
+// are recursed; for everything else src wins. dst is mutated and returned.
+func deepMergeJSON(dst, src map[string]interface{}) map[string]interface{} {
+	for k, srcVal := range src {
+		if dstVal, exists := dst[k]; exists {
+			srcMap, srcIsMap := srcVal.(map[string]interface{})
+			dstMap, dstIsMap := dstVal.(map[string]interface{})
+			if srcIsMap && dstIsMap {
+				dst[k] = deepMergeJSON(dstMap, srcMap)
+				continue
+			}
 		}
-		tmpFile.Close()
-		hostPath = tmpFile.Name()
-	}
-
-	opts.Logger("[seven init] syncing claude credentials into sprite")
-	copySpec := hostPath + ":/tmp/host-claude-credentials.json"
-	cmdArgs := []string{
-		"exec",
-		"-s", spriteName,
-		"-file", copySpec,
-		"--",
-		"sh", "-lc", "install -d -m 700 \"$HOME/.claude\" && install -m 600 /tmp/host-claude-credentials.json \"$HOME/.claude/.credentials.json\" && rm -f /tmp/host-claude-credentials.json",
-	}
-	if opts.QuietExternal {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
-	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
-}
-
-func ensureCodexConfigInSprite(spriteName, hostConfigPath string, opts upOptions) error {
-	if hostConfigPath == "" {
-		return nil
-	}
-	if err := spriteExec(spriteName, nil, opts.QuietExternal, "sh", "-lc", "command -v codex >/dev/null 2>&1"); err != nil {
-		opts.Logger("[seven init] codex not found in sprite, skipping codex config sync")
-		return nil
-	}
-
-	opts.Logger("[seven init] syncing codex config into sprite")
-	copySpec := hostConfigPath + ":/tmp/host-codex-config.toml"
-	cmdArgs := []string{
-		"exec",
-		"-s", spriteName,
-		"-file", copySpec,
-		"--",
-		"sh", "-lc", "install -d -m 700 \"$HOME/.codex\" && install -m 600 /tmp/host-codex-config.toml \"$HOME/.codex/config.toml\" && rm -f /tmp/host-codex-config.toml",
-	}
-	if opts.QuietExternal {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
-	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
-}
-
-func ensureCodexAuthInSprite(spriteName, hostAuthPath string, opts upOptions) error {
-	if hostAuthPath == "" {
-		return nil
-	}
-	if err := spriteExec(spriteName, nil, opts.QuietExternal, "sh", "-lc", "command -v codex >/dev/null 2>&1"); err != nil {
-		opts.Logger("[seven init] codex not found in sprite, skipping codex auth sync")
-		return nil
-	}
-
-	opts.Logger("[seven init] syncing codex auth into sprite")
-	copySpec := hostAuthPath + ":/tmp/host-codex-auth.json"
-	cmdArgs := []string{
-		"exec",
-		"-s", spriteName,
-		"-file", copySpec,
-		"--",
-		"sh", "-lc", "install -d -m 700 \"$HOME/.codex\" && install -m 600 /tmp/host-codex-auth.json \"$HOME/.codex/auth.json\" && rm -f /tmp/host-codex-auth.json",
-	}
-	if opts.QuietExternal {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
+		dst[k] = srcVal
 	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
+	return dst
 }
 
-func syncGitIdentity(spriteName string, opts upOptions) error {
+func syncGitIdentity(ctx context.Context, spriteName string, opts upOptions) error {
 	if _, err := exec.LookPath("git"); err != nil {
 		return nil
 	}
 
-	name, _ := readGitConfig("user.name")
-	email, _ := readGitConfig("user.email")
+	name, _ := readGitConfig(ctx, "user.name")
+	email, _ := readGitConfig(ctx, "user.email")
 	if name == "" && email == "" {
 		return nil
 	}
 
 	opts.Logger("[seven init] syncing git identity into sprite")
 	if name != "" {
-		if err := spriteExec(spriteName, nil, opts.QuietExternal, "git", "config", "--global", "user.name", name); err != nil {
+		if err := spriteExec(ctx, spriteName, nil, opts.QuietExternal, "git", "config", "--global", "user.name", name); err != nil {
 			return err
 		}
 	}
 	if email != "" {
-		if err := spriteExec(spriteName, nil, opts.QuietExternal, "git", "config", "--global", "user.email", email); err != nil {
+		if err := spriteExec(ctx, spriteName, nil, opts.QuietExternal, "git", "config", "--global", "user.email", email); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func readGitConfig(key string) (string, error) {
-	val, err := runCmdOutput("git", nil, "config", "--get", key)
+func readGitConfig(ctx context.Context, key string) (string, error) {
+	val, err := runCmdOutput(ctx, "git", nil, "config", "--get", key)
 	if err != nil {
 		return "", err
 	}
@@ -2464,48 +3045,30 @@ func githubRepoSlug(repoURL string) string {
 	return parts[0] + "/" + parts[1]
 }
 
-func spriteExec(spriteName string, env []string, quiet bool, args ...string) error {
-	if spriteName == "" {
-		return errors.New("sprite name is empty")
-	}
-	cmdArgs := []string{"exec", "-s", spriteName}
-	for _, kv := range env {
-		cmdArgs = append(cmdArgs, "-env", kv)
-	}
-	cmdArgs = append(cmdArgs, "--")
-	cmdArgs = append(cmdArgs, args...)
+func spriteExec(ctx context.Context, spriteName string, env []string, quiet bool, args ...string) error {
+	return activeBackend.Exec(ctx, spriteName, withStdio(backend.ExecRequest{Command: args, Env: env}, quiet))
+}
 
-	if quiet {
-		_, err := runCmdOutput(spriteBin(), nil, cmdArgs...)
-		return err
-	}
-	return runCmd(spriteBin(), nil, cmdArgs...)
+// spriteExecWithFiles uploads files into the sprite and then runs args.
+func spriteExecWithFiles(ctx context.Context, spriteName string, files []backend.File, quiet bool, args ...string) error {
+	return activeBackend.Exec(ctx, spriteName, withStdio(backend.ExecRequest{Command: args, Files: files}, quiet))
 }
 
-func spriteExecOutput(spriteName string, env []string, args ...string) (string, error) {
-	if spriteName == "" {
-		return "", errors.New("sprite name is empty")
-	}
-	cmdArgs := []string{"exec", "-s", spriteName}
-	for _, kv := range env {
-		cmdArgs = append(cmdArgs, "-env", kv)
-	}
-	cmdArgs = append(cmdArgs, "--")
-	cmdArgs = append(cmdArgs, args...)
-	return runCmdOutput(spriteBin(), nil, cmdArgs...)
+func spriteExecOutput(ctx context.Context, spriteName string, env []string, args ...string) (string, error) {
+	return activeBackend.ExecOutput(ctx, spriteName, backend.ExecRequest{Command: args, Env: env})
 }
 
-func spriteExecOutputHTTPPost(spriteName string, env []string, args ...string) (string, error) {
-	if spriteName == "" {
-		return "", errors.New("sprite name is empty")
-	}
-	cmdArgs := []string{"exec", "-s", spriteName, "--http-post"}
-	for _, kv := range env {
-		cmdArgs = append(cmdArgs, "-env", kv)
+func spriteExecOutputLongRunning(ctx context.Context, spriteName string, env []string, args ...string) (string, error) {
+	return activeBackend.ExecOutput(ctx, spriteName, backend.ExecRequest{Command: args, Env: env, LongRunning: true})
+}
+
+// spriteArgs prefixes a sprite CLI argument list with the active profile's
+// org, so every sprite command of one seven run targets the same org.
+func spriteArgs(args ...string) []string {
+	if spriteOrg == "" {
+		return args
 	}
-	cmdArgs = append(cmdArgs, "--")
-	cmdArgs = append(cmdArgs, args...)
-	return runCmdOutput(spriteBin(), nil, cmdArgs...)
+	return append([]string{"-o", spriteOrg}, args...)
 }
 
 func spriteBin() string {
@@ -2526,62 +3089,53 @@ func installSpriteCLI() error {
 	return cmd.Run()
 }
 
-func runCmd(name string, extraEnv []string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	if len(extraEnv) > 0 {
-		cmd.Env = append(os.Environ(), extraEnv...)
-	}
-	return cmd.Run()
+// errInterrupted is returned by a run that Ctrl-C or SIGTERM stopped.
+var errInterrupted = errors.New("interrupted")
+
+// cleanupTimeout bounds the cleanup an interrupted run still does, such as
+// recording init progress or destroying an incomplete sprite.
+const cleanupTimeout = 2 * time.Minute
+
+// interruptContext is cancelled by Ctrl-C or SIGTERM, so a command stops its
+// child processes and runs its own cleanup instead of dying mid-step.
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 }
 
-func runCmdWithInput(name string, extraEnv []string, stdin string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = strings.NewReader(stdin)
-	if len(extraEnv) > 0 {
-		cmd.Env = append(os.Environ(), extraEnv...)
-	}
-	return cmd.Run()
+// commandContext is exec.CommandContext with a gentler cancel: the child gets
+// SIGINT first, so the sprite CLI can close its remote session, and is only
+// killed if it is still running a few seconds later.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
 }
 
-func runCmdDevNull(name string, extraEnv []string, args ...string) error {
-	cmd := exec.Command(name, args...)
+func runCmd(ctx context.Context, name string, extraEnv []string, args ...string) error {
+	cmd := commandContext(ctx, name, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	devNull, err := os.Open(os.DevNull)
-	if err != nil {
-		return err
-	}
-	defer devNull.Close()
-	cmd.Stdin = devNull
+	cmd.Stdin = os.Stdin
 	if len(extraEnv) > 0 {
 		cmd.Env = append(os.Environ(), extraEnv...)
 	}
 	return cmd.Run()
 }
 
-func runCmdQuiet(name string, extraEnv []string, args ...string) error {
-	cmd := exec.Command(name, args...)
-	cmd.Stdout = nil
-	cmd.Stderr = nil
-	devNull, err := os.Open(os.DevNull)
-	if err != nil {
-		return err
-	}
-	defer devNull.Close()
-	cmd.Stdin = devNull
+func runCmdWithInput(ctx context.Context, name string, extraEnv []string, stdin string, args ...string) error {
+	cmd := commandContext(ctx, name, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = strings.NewReader(stdin)
 	if len(extraEnv) > 0 {
 		cmd.Env = append(os.Environ(), extraEnv...)
 	}
 	return cmd.Run()
 }
 
-func runCmdOutput(name string, extraEnv []string, args ...string) (string, error) {
-	cmd := exec.Command(name, args...)
+func runCmdOutput(ctx context.Context, name string, extraEnv []string, args ...string) (string, error) {
+	cmd := commandContext(ctx, name, args...)
 	if len(extraEnv) > 0 {
 		cmd.Env = append(os.Environ(), extraEnv...)
 	}
@@ -2603,6 +3157,9 @@ type upModel struct {
 	logs    []string
 	res     upResult
 	err     error
+
+	cancel     context.CancelFunc // stops the run on ctrl+c
+	cancelling bool
 }
 
 var (
@@ -2614,10 +3171,10 @@ var (
 	styleEnabled = true
 )
 
-func newUpModel() upModel {
+func newUpModel(cancel context.CancelFunc) upModel {
 	sp := spinner.New()
 	sp.Spinner = spinner.Line
-	return upModel{spinner: sp, logs: []string{}}
+	return upModel{spinner: sp, logs: []string{}, cancel: cancel}
 }
 
 func (m upModel) Init() tea.Cmd {
@@ -2628,7 +3185,14 @@ func (m upModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
-			return m, tea.Quit
+			// Keep running until runUp returns, so its cleanup and the state
+			// it leaves behind are shown.
+			if !m.cancelling {
+				m.cancelling = true
+				m.cancel()
+				m.logs = append(m.logs, "[seven up] interrupting; cleaning up")
+			}
+			return m, nil
 		}
 	case spinner.TickMsg:
 		var cmd tea.Cmd
@@ -2648,6 +3212,9 @@ func (m upModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m upModel) View() string {
 	b := &strings.Builder{}
 	title := fmt.Sprintf("%s seven up", m.spinner.View())
+	if m.cancelling {
+		title = fmt.Sprintf("%s interrupting seven up", m.spinner.View())
+	}
 	if m.err != nil {
 		title = fmt.Sprintf("! seven up")
 	}
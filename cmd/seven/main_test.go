@@ -2,8 +2,10 @@ package main
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -13,6 +15,9 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 var testSevenBin string
@@ -140,6 +145,75 @@ func runSevenUpForLog(t *testing.T, repo, state, logPath string, extraEnv []stri
 	return string(logData)
 }
 
+func TestSevenUpInterruptKeepsSpriteAndStopsExec(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	cmd := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--no-console")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"HOME="+t.TempDir(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_EXEC_HANG_ON=gh repo clone",
+	)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := os.Stat(state + ".hung"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+			t.Fatalf("clone never started:\n%s", out.String())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	started := time.Now()
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	err := cmd.Wait()
+	if err == nil || time.Since(started) > 5*time.Second {
+		t.Fatalf("expected the interrupt to stop seven up promptly, err=%v after %s:\n%s", err, time.Since(started), out.String())
+	}
+	for _, want := range []string{
+		"initialization was interrupted after step assistant-sync; kept sprite",
+		"seven up failed: interrupted after init step assistant-sync",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out.String())
+		}
+	}
+	logData, _ := os.ReadFile(logPath)
+	log := string(logData)
+	clone := strings.Index(log, "gh repo clone")
+	if clone < 0 || !strings.Contains(log[clone:], sevenInitProgressPath) || strings.Contains(log, "destroy ") {
+		t.Fatalf("expected progress to be recorded after the interrupted clone and the sprite kept:\n%s", log)
+	}
+}
+
+func TestUpModelCtrlCCancelsAndWaitsForCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var m tea.Model = newUpModel(cancel)
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if ctx.Err() == nil || cmd != nil {
+		t.Fatalf("expected ctrl+c to cancel the run without quitting, cmd=%v", cmd)
+	}
+	if view := m.View(); !strings.Contains(view, "interrupting seven up") {
+		t.Fatalf("expected the view to show the interrupt, got:\n%s", view)
+	}
+	if _, cmd := m.Update(doneMsg{err: errInterrupted}); cmd == nil {
+		t.Fatal("expected the TUI to quit once the run returns")
+	}
+}
+
 func TestSevenUpGstackInstallsWhenFlagSet(t *testing.T) {
 	repo := createTempRepo(t)
 	state, logPath, cleanup := createFakeSprite(t)
@@ -225,7 +299,7 @@ func TestSevenUpRejectsStaleClonedHead(t *testing.T) {
 	state, logPath, cleanup := createFakeSprite(t)
 	defer cleanup()
 
-	cmd := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--no-console", "--from-host")
+	cmd := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--no-console", "--from-host", "--destroy-on-failure")
 	cmd.Dir = repo
 	cmd.Env = append(os.Environ(),
 		"HOME="+t.TempDir(),
@@ -239,8 +313,9 @@ func TestSevenUpRejectsStaleClonedHead(t *testing.T) {
 		t.Fatalf("expected stale remote branch to fail closed, err=%v output=%s", err, out)
 	}
 
-	// A failed first initialization must be recoverable: cleanup destroys the
-	// incomplete Sprite, so the next run creates and provisions from scratch.
+	// With --destroy-on-failure a failed first initialization must be
+	// recoverable: cleanup destroys the incomplete Sprite, so the next run
+	// creates and provisions from scratch.
 	retry := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--no-console", "--from-host")
 	retry.Dir = repo
 	retry.Env = append(os.Environ(),
@@ -393,6 +468,20 @@ func TestValidateProjectToolingManifest(t *testing.T) {
 	if _, err := validateProjectToolingManifest(validNestedArchive); err != nil {
 		t.Fatalf("expected safe nested archive member and gnuarch template to validate: %v", err)
 	}
+	validApt := "apt postgresql-client postgresql-client=16+257build1.1 psql --version\napt tzdata tzdata=1:2024a-0ubuntu0.24.04 date --version"
+	if _, err := validateProjectToolingManifest(validApt); err != nil {
+		t.Fatalf("expected apt rows with Debian versions and a separate verifier to validate: %v", err)
+	}
+	validLanguageTools := "go golangci-lint github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.5.0 golangci-lint version\n" +
+		"go tool example.com/tool/v3@v3.0.1 tool --version\ncargo ripgrep ripgrep@14.1.1 rg --version\n" +
+		"uv-tool ruff ruff==0.15.18 ruff --version\n"
+	if _, err := validateProjectToolingManifest(validLanguageTools); err != nil {
+		t.Fatalf("expected go, cargo, and uv-tool rows to validate: %v", err)
+	}
+	validZip := "archive tool v1.0.0|https://example.com/tool-{arch}|" + archiveSHA + "|" + archiveSHA + "|tool|-|zip tool --version"
+	if _, err := validateProjectToolingManifest(validZip); err != nil {
+		t.Fatalf("expected an archive row with an explicit format to validate: %v", err)
+	}
 	for name, manifest := range map[string]string{
 		"duplicate gstack":    "gstack gstack " + sha + " -\ngstack gstack " + sha + " -\n",
 		"duplicate tool":      "npm tool tool@1.2.3 tool --version\npip tool tool==1.2.3 tool --version\n",
@@ -406,6 +495,22 @@ func TestValidateProjectToolingManifest(t *testing.T) {
 		"archive traversal":   "archive shellcheck v0.11.0|https://example.com/shellcheck-{gnuarch}.tar.gz|" + archiveSHA + "|" + archiveSHA + "|pkg/../shellcheck|- shellcheck --version\n",
 		"archive absolute":    "archive shellcheck v0.11.0|https://example.com/shellcheck-{gnuarch}.tar.gz|" + archiveSHA + "|" + archiveSHA + "|/pkg/shellcheck|- shellcheck --version\n",
 		"archive option":      "archive shellcheck v0.11.0|https://example.com/shellcheck-{gnuarch}.tar.gz|" + archiveSHA + "|" + archiveSHA + "|--checkpoint/pkg|- shellcheck --version\n",
+		"unpinned apt":        "apt jq jq jq --version\n",
+		"apt name mismatch":   "apt jq jql=1.7.1-3 jq --version\n",
+		"apt metacharacter":   "apt jq jq=1.7.1;rm jq --version\n",
+		"apt uppercase":       "apt Jq Jq=1.7.1-3 jq --version\n",
+		"apt unsafe verifier": "apt jq jq=1.7.1-3 eval --version\n",
+		"go without v":        "go gopls golang.org/x/tools/gopls@0.20.0 gopls version\n",
+		"go latest":           "go gopls golang.org/x/tools/gopls@latest gopls version\n",
+		"go binary mismatch":  "go lint golang.org/x/tools/gopls@v0.20.0 lint version\n",
+		"go no domain":        "go gopls tools/gopls@v0.20.0 gopls version\n",
+		"go metacharacter":    "go gopls golang.org/x/$(id)/gopls@v0.20.0 gopls version\n",
+		"uv-tool unpinned":    "uv-tool ruff ruff>=0.15.18 ruff --version\n",
+		"uv-tool verifier":    "uv-tool ruff ruff==0.15.18 ruff-lsp --version\n",
+		"cargo unpinned":      "cargo ripgrep ripgrep rg --version\n",
+		"cargo v prefix":      "cargo ripgrep ripgrep@v14.1.1 rg --version\n",
+		"cargo dotted crate":  "cargo rip.grep rip.grep@14.1.1 rg --version\n",
+		"archive format":      "archive tool v1.0.0|https://example.com/tool-{arch}.rar|" + archiveSHA + "|" + archiveSHA + "|tool|-|rar tool --version\n",
 		"archive placeholder": "archive shellcheck v0.11.0|https://example.com/shellcheck-{platform}.tar.gz|" + archiveSHA + "|" + archiveSHA + "|pkg/shellcheck|- shellcheck --version\n",
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -668,6 +773,198 @@ pip     ruff          ruff==0.15.18       ruff --version
 		}
 	})
 
+	t.Run("reconciles apt packages with sudo apt-get", func(t *testing.T) {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		dpkgDir := filepath.Join(dir, "dpkg")
+		for _, d := range []string{binDir, dpkgDir} {
+			if err := os.MkdirAll(d, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		aptLog := filepath.Join(dir, "apt.log")
+		// The fake dpkg database is one file per installed package holding its
+		// version; apt-get install writes it, except for the broken package.
+		writeExecutable(t, filepath.Join(binDir, "dpkg-query"), `#!/bin/sh
+for arg; do pkg="$arg"; done
+[ -f "`+dpkgDir+`/$pkg" ] || exit 1
+IFS= read -r version < "`+dpkgDir+`/$pkg"
+printf 'install ok installed %s' "$version"
+`)
+		writeExecutable(t, filepath.Join(binDir, "apt-get"), `#!/bin/sh
+printf '%s\n' "$*" >> "`+aptLog+`"
+[ "$1" = install ] || exit 0
+for arg; do spec="$arg"; done
+case "$spec" in broken=*) exit 100 ;; esac
+printf '%s' "${spec#*=}" > "`+dpkgDir+`/${spec%%=*}"
+`)
+		writeExecutable(t, filepath.Join(binDir, "sudo"), `#!/bin/sh
+printf 'sudo %s\n' "$*" >> "`+aptLog+`"
+[ "$1" = -n ] && shift
+exec "$@"
+`)
+		writeExecutable(t, filepath.Join(binDir, "env"), "#!/bin/sh\nshift\nexec \"$@\"\n")
+		writeExecutable(t, filepath.Join(binDir, "id"), "#!/bin/sh\nprintf '1000\\n'\n")
+		for _, tool := range []string{"jq", "psql", "broken"} {
+			writeExecutable(t, filepath.Join(binDir, tool), "#!/bin/sh\nexit 0\n")
+		}
+		if err := os.WriteFile(filepath.Join(dpkgDir, "postgresql-client"), []byte("16+257build1.1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dpkgDir, "jq"), []byte("1.6-2.1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest := "apt postgresql-client postgresql-client=16+257build1.1 psql --version\n" +
+			"apt jq jq=1.7.1-3build1 jq --version\n"
+		out := runInstallScript(t, projectToolingInstallScript(manifest), binDir)
+		if !strings.Contains(out, "present: postgresql-client | installed: jq | failed: none") {
+			t.Fatalf("expected the pinned package kept and the stale one reconciled, got: %s", out)
+		}
+		data, err := os.ReadFile(aptLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log := string(data)
+		if strings.Count(log, "sudo -n env DEBIAN_FRONTEND=noninteractive apt-get update") != 1 ||
+			!strings.Contains(log, "sudo -n env DEBIAN_FRONTEND=noninteractive apt-get install -y -qq --no-install-recommends --allow-downgrades jq=1.7.1-3build1") ||
+			strings.Contains(log, "install -y -qq --no-install-recommends --allow-downgrades postgresql-client") {
+			t.Fatalf("expected one update and an install of only the stale pin, got apt log:\n%s", log)
+		}
+
+		out, err = runInstallScriptResult(t, projectToolingInstallScript("apt broken broken=1.0-1 broken --version\n"), binDir)
+		if err == nil || !strings.Contains(out, "failed: broken") {
+			t.Fatalf("expected a failed apt install to fail closed, err=%v output=%s", err, out)
+		}
+	})
+
+	t.Run("reconciles go and cargo tools at exact versions", func(t *testing.T) {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		goBin := filepath.Join(dir, "gobin")
+		goState := filepath.Join(dir, "gostate")
+		cargoHome := filepath.Join(dir, "cargo")
+		for _, d := range []string{binDir, goBin, goState, filepath.Join(cargoHome, "bin")} {
+			if err := os.MkdirAll(d, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		installLog := filepath.Join(dir, "install.log")
+		cargoList := filepath.Join(dir, "cargo.list")
+		// The fake go records the package and version each binary was built
+		// from and reports them back the way `go version -m` does.
+		writeExecutable(t, filepath.Join(binDir, "go"), `#!/bin/sh
+case "$1" in
+  env) [ "$2" = GOBIN ] && printf '%s\n' "`+goBin+`"; exit 0 ;;
+  install)
+    printf 'go install %s\n' "$2" >> "`+installLog+`"
+    pkg="${2%@*}"; name="${pkg##*/}"
+    printf '%s %s\n' "$pkg" "${2##*@}" > "`+goState+`/$name"
+    printf '#!/bin/sh\nexit 0\n' > "`+goBin+`/$name"; chmod +x "`+goBin+`/$name" ;;
+  version)
+    read -r pkg version < "`+goState+`/${3##*/}" || exit 1
+    printf '%s: go1.24.0\n\tpath\t%s\n\tmod\t%s\t%s\th1:x=\n' "$3" "$pkg" "$pkg" "$version" ;;
+esac
+`)
+		writeExecutable(t, filepath.Join(binDir, "cargo"), `#!/bin/sh
+[ "$2" = --list ] && { cat "`+cargoList+`"; exit 0; }
+printf 'cargo %s\n' "$*" >> "`+installLog+`"
+for arg; do crate="$arg"; done
+[ "$crate" = broken ] && exit 101
+printf '%s v%s:\n    %s\n' "$crate" "$4" "$crate" >> "`+cargoList+`"
+printf '#!/bin/sh\nexit 0\n' > "`+cargoHome+`/bin/$crate"; chmod +x "`+cargoHome+`/bin/$crate"
+`)
+		writeExecutable(t, filepath.Join(goBin, "golangci-lint"), "#!/bin/sh\nexit 0\n")
+		writeExecutable(t, filepath.Join(goBin, "gopls"), "#!/bin/sh\nexit 0\n")
+		writeExecutable(t, filepath.Join(cargoHome, "bin", "rg"), "#!/bin/sh\nexit 0\n")
+		for name, contents := range map[string]string{
+			filepath.Join(goState, "golangci-lint"): "github.com/golangci/golangci-lint/v2/cmd/golangci-lint v2.4.0\n",
+			filepath.Join(goState, "gopls"):         "golang.org/x/tools/gopls v0.20.0\n",
+			cargoList:                               "ripgrep v14.1.1:\n    rg\n",
+		} {
+			if err := os.WriteFile(name, []byte(contents), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		run := func(manifest string) (string, error) {
+			sh, err := exec.LookPath("sh")
+			if err != nil {
+				t.Skip("sh not available")
+			}
+			cmd := exec.Command(sh, "-c", projectToolingInstallScript(manifest))
+			cmd.Env = []string{"HOME=" + dir, "CARGO_HOME=" + cargoHome, "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")}
+			out, err := cmd.CombinedOutput()
+			return string(out), err
+		}
+		out, err := run("go gopls golang.org/x/tools/gopls@v0.20.0 gopls version\n" +
+			"go golangci-lint github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.5.0 golangci-lint version\n" +
+			"cargo ripgrep ripgrep@14.1.1 rg --version\n" +
+			"cargo cargo-nextest cargo-nextest@0.9.104 cargo-nextest --version\n")
+		if err != nil || !strings.Contains(out, "present: gopls ripgrep | installed: golangci-lint cargo-nextest | failed: none") {
+			t.Fatalf("expected exact pins kept and drift reconciled, err=%v output=%s", err, out)
+		}
+		data, err := os.ReadFile(installLog)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "go install github.com/golangci/golangci-lint/v2/cmd/golangci-lint@v2.5.0\ncargo install --locked --version 0.9.104 cargo-nextest\n"
+		if string(data) != want {
+			t.Fatalf("expected only the drifted tools installed, got:\n%s", data)
+		}
+
+		out, err = run("cargo broken broken@1.0.0 broken --version\n")
+		if err == nil || !strings.Contains(out, "failed: broken") {
+			t.Fatalf("expected a failed cargo install to fail closed, err=%v output=%s", err, out)
+		}
+	})
+
+	t.Run("installs uv tools into isolated environments", func(t *testing.T) {
+		dir := t.TempDir()
+		binDir := filepath.Join(dir, "bin")
+		uvBin := filepath.Join(dir, "uvbin")
+		for _, d := range []string{binDir, uvBin} {
+			if err := os.MkdirAll(d, 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		uvLog := filepath.Join(dir, "uv.log")
+		writeExecutable(t, filepath.Join(binDir, "uv"), `#!/bin/sh
+[ "$2 $3" = "dir --bin" ] && { printf '%s\n' "`+uvBin+`"; exit 0; }
+printf '%s\n' "$*" >> "`+uvLog+`"
+for arg; do spec="$arg"; done
+printf '#!/bin/sh\nprintf "%s %s\\n"\n' "${spec%%==*}" "${spec##*==}" > "`+uvBin+`/${spec%%==*}"
+chmod +x "`+uvBin+`/${spec%%==*}"
+`)
+		writeExecutable(t, filepath.Join(uvBin, "mypy"), "#!/bin/sh\nprintf 'mypy 1.18.2 (compiled: yes)\\n'\n")
+		sh, err := exec.LookPath("sh")
+		if err != nil {
+			t.Skip("sh not available")
+		}
+		cmd := exec.Command(sh, "-c", projectToolingInstallScript("uv-tool mypy mypy==1.18.2 mypy --version\nuv-tool ruff ruff==0.15.18 ruff --version\n"))
+		cmd.Env = []string{"HOME=" + dir, "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")}
+		out, err := cmd.CombinedOutput()
+		if err != nil || !strings.Contains(string(out), "present: mypy | installed: ruff | failed: none") {
+			t.Fatalf("expected mypy kept and ruff installed from the uv tool bin dir, err=%v output=%s", err, out)
+		}
+		if data, _ := os.ReadFile(uvLog); string(data) != "tool install --force -- ruff==0.15.18\n" {
+			t.Fatalf("expected one isolated uv install, got %q", data)
+		}
+	})
+
+	t.Run("uv tools fail closed without uv instead of using pip", func(t *testing.T) {
+		binDir := t.TempDir()
+		pipLog := filepath.Join(binDir, "pip.log")
+		writeExecutable(t, filepath.Join(binDir, "python3"), "#!/bin/sh\nprintf '%s\\n' \"$*\" >> \""+pipLog+"\"\n")
+		out, err := runInstallScriptResult(t, projectToolingInstallScript("uv-tool ruff ruff==0.15.18 ruff --version\n"), binDir)
+		if err == nil || !strings.Contains(out, "ruff: uv is not installed in the sprite") || !strings.Contains(out, "failed: ruff") {
+			t.Fatalf("expected a clear uv failure, err=%v output=%s", err, out)
+		}
+		if _, err := os.Stat(pipLog); !os.IsNotExist(err) {
+			t.Fatalf("uv-tool rows must never fall back to pip, stat err=%v", err)
+		}
+	})
+
 	t.Run("installs a checksum-verified nested archive member", func(t *testing.T) {
 		dir := t.TempDir()
 		binDir := filepath.Join(dir, "bin")
@@ -773,6 +1070,112 @@ printf '%s\n' "$url" > "`+urlLog+`"
 		}
 	})
 
+	t.Run("extracts zip and tar.xz members and rejects unsafe zips", func(t *testing.T) {
+		content := []byte("#!/bin/sh\nprintf 'archive-tool 1.2.3\\n'\n")
+		member := zipFixtureEntry{name: "pkg/archive-tool", mode: 0o755, content: content}
+		for name, tc := range map[string]struct {
+			write  func(t *testing.T, path string) []byte
+			url    string
+			format string
+			ok     bool
+		}{
+			"zip from url": {
+				write: func(t *testing.T, path string) []byte { return writeZipFixture(t, path, []zipFixtureEntry{member}) },
+				url:   "archive-{arch}.zip", ok: true,
+			},
+			"zip named explicitly": {
+				write: func(t *testing.T, path string) []byte { return writeZipFixture(t, path, []zipFixtureEntry{member}) },
+				url:   "archive-{arch}", format: "zip", ok: true,
+			},
+			"zip without unix modes": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "pkg/archive-tool", content: content}})
+				},
+				url: "archive-{arch}.zip", ok: true,
+			},
+			"tar.xz from url": {
+				write: func(t *testing.T, path string) []byte {
+					return writeTarXzFixture(t, path, []tarFixtureEntry{{
+						header: tar.Header{Name: "pkg/archive-tool", Mode: 0o755, Typeflag: tar.TypeReg}, content: content,
+					}})
+				},
+				url: "archive-{gnuarch}.tar.xz", ok: true,
+			},
+			"zip traversal": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "../evil", mode: 0o755, content: content}, member})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"zip absolute path": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "/evil", mode: 0o755, content: content}, member})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"zip symlink member": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "pkg/archive-tool", mode: os.ModeSymlink | 0o777, content: []byte("payload")}})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"zip symlink elsewhere": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "pkg/link", mode: os.ModeSymlink | 0o777, content: []byte("/etc")}, member})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"zip duplicate member": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{member, member})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"zip directory member": {
+				write: func(t *testing.T, path string) []byte {
+					return writeZipFixture(t, path, []zipFixtureEntry{{name: "pkg/archive-tool/", mode: os.ModeDir | 0o755}})
+				},
+				url: "archive-{arch}.zip",
+			},
+			"tarball named zip": {
+				write: func(t *testing.T, path string) []byte {
+					return writeTarFixture(t, path, []tarFixtureEntry{{
+						header: tar.Header{Name: "pkg/archive-tool", Mode: 0o755, Typeflag: tar.TypeReg}, content: content,
+					}})
+				},
+				url: "archive-{arch}.tar.gz", format: "zip",
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				dir := t.TempDir()
+				archivePath := filepath.Join(dir, "fixture")
+				sum := fmt.Sprintf("%x", sha256.Sum256(tc.write(t, archivePath)))
+				binDir := filepath.Join(dir, "bin")
+				if err := os.MkdirAll(binDir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeArchiveTestCommands(t, binDir, archivePath)
+				spec := "1.2.3|https://example.com/" + tc.url + "|" + sum + "|" + sum + "|pkg/archive-tool|-"
+				if tc.format != "" {
+					spec += "|" + tc.format
+				}
+				home := filepath.Join(dir, "home")
+				cmd := exec.Command("sh", "-c", projectToolingInstallScript("archive archive-tool "+spec+" archive-tool --version\n"))
+				cmd.Env = []string{"HOME=" + home, "PATH=" + binDir + string(os.PathListSeparator) + os.Getenv("PATH")}
+				out, err := cmd.CombinedOutput()
+				if tc.ok && (err != nil || !strings.Contains(string(out), "installed: archive-tool")) {
+					t.Fatalf("expected the member installed, err=%v output=%s", err, out)
+				}
+				if !tc.ok && (err == nil || !strings.Contains(string(out), "failed: archive-tool")) {
+					t.Fatalf("expected the archive to fail closed, err=%v output=%s", err, out)
+				}
+				if _, err := os.Stat(filepath.Join(home, ".local", "bin", "archive-tool")); tc.ok == os.IsNotExist(err) {
+					t.Fatalf("installed binary presence does not match ok=%v, stat err=%v", tc.ok, err)
+				}
+			})
+		}
+	})
+
 	t.Run("rejects an archive alias that collides with a directory", func(t *testing.T) {
 		dir := t.TempDir()
 		archivePath := filepath.Join(dir, "fixture.tar.gz")
@@ -843,6 +1246,64 @@ func writeTarFixture(t *testing.T, path string, entries []tarFixtureEntry) []byt
 	return archive.Bytes()
 }
 
+type zipFixtureEntry struct {
+	name    string
+	mode    os.FileMode
+	content []byte
+}
+
+// writeZipFixture writes a zip with the given entries; a zero mode leaves the
+// entry without Unix permissions, as zips built on Windows do.
+func writeZipFixture(t *testing.T, path string, entries []zipFixtureEntry) []byte {
+	t.Helper()
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, archive.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return archive.Bytes()
+}
+
+// writeTarXzFixture is writeTarFixture recompressed with the host's xz, which
+// the standard library cannot write.
+func writeTarXzFixture(t *testing.T, path string, entries []tarFixtureEntry) []byte {
+	t.Helper()
+	xz, err := exec.LookPath("xz")
+	if err != nil {
+		t.Skip("xz not available")
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(writeTarFixture(t, path, entries)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(xz, "-c")
+	cmd.Stdin = gz
+	archive, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("xz: %v", err)
+	}
+	if err := os.WriteFile(path, archive, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
 func writeArchiveTestCommands(t *testing.T, binDir, archivePath string) {
 	t.Helper()
 	writeExecutable(t, filepath.Join(binDir, "curl"), `#!/bin/sh
@@ -1515,12 +1976,34 @@ func TestSevenUpExplicitCodexAssistantOverridesUsableClaudeAuth(t *testing.T) {
 	}
 }
 
+func TestSevenUpCursorAssistantHintsCursorAgent(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	log := runSevenUpForLog(t, repo, state, logPath, nil, "--no-console", "--assistant", "cursor")
+	if !strings.Contains(log, "SEVEN_ASSISTANT=cursor-agent") {
+		t.Fatalf("expected the console hint to name cursor-agent, got: %s", log)
+	}
+	if !strings.Contains(log, "alias c3='cursor-agent --force'") || !strings.Contains(log, "alias c4='gemini --yolo'") {
+		t.Fatalf("expected cursor and gemini aliases in sprite identity, got: %s", log)
+	}
+}
+
+func TestNormalizeAssistant(t *testing.T) {
+	for in, want := range map[string]string{"": "", "Claude": "claude", "codex": "codex", "gemini": "gemini", "gemini-cli": "gemini", "cursor": "cursor", " cursor-agent ": "cursor"} {
+		if got, err := normalizeAssistant(in); err != nil || got != want {
+			t.Fatalf("normalizeAssistant(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
 func TestSevenUpRejectsUnknownAssistant(t *testing.T) {
 	repo := createTempRepo(t)
-	cmd := exec.Command(testSevenBin, "up", "--no-tui", "--assistant", "cursor")
+	cmd := exec.Command(testSevenBin, "up", "--no-tui", "--assistant", "copilot")
 	cmd.Dir = repo
 	output, err := cmd.CombinedOutput()
-	if err == nil || !bytes.Contains(output, []byte(`unsupported assistant "cursor"`)) {
+	if err == nil || !bytes.Contains(output, []byte(`unsupported assistant "copilot"`)) {
 		t.Fatalf("expected unsupported assistant error, err=%v output=%s", err, output)
 	}
 }
@@ -2245,8 +2728,7 @@ func TestMergedJSONForSprite(t *testing.T) {
 	}
 
 	t.Run("returns host path when sprite has no file", func(t *testing.T) {
-		// spriteExecOutput will fail since there's no real sprite
-		path, cleanup, err := mergedJSONForSprite("nonexistent", hostFile, `cat /nonexistent 2>/dev/null`)
+		path, cleanup, err := mergedJSONForSprite(hostFile, "", nil)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -2257,6 +2739,49 @@ func TestMergedJSONForSprite(t *testing.T) {
 			t.Fatalf("expected original host path when sprite file missing, got: %s", path)
 		}
 	})
+
+	t.Run("merges into the sprite copy", func(t *testing.T) {
+		path, cleanup, err := mergedJSONForSprite(hostFile, `{"theme":"light","skipDangerousModePermissionPrompt":true}`, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cleanup == nil || path == hostFile {
+			t.Fatalf("expected a merged temporary file, got: %s", path)
+		}
+		defer cleanup()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read merged file: %v", err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("merged file is not JSON: %v", err)
+		}
+		if got["theme"] != "dark-ansi" || got["skipDangerousModePermissionPrompt"] != true {
+			t.Fatalf("unexpected merge result: %v", got)
+		}
+	})
+
+	t.Run("leaves omitted keys to the sprite", func(t *testing.T) {
+		withServers := filepath.Join(t.TempDir(), "claude.json")
+		if err := os.WriteFile(withServers, []byte(`{"theme":"dark-ansi","mcpServers":{"local":{"command":"/Users/dev/bin/mcp"}}}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		for _, spriteData := range []string{"", `{"mcpServers":{"docs":{"url":"https://docs.example.com/mcp"}}}`} {
+			path, cleanup, err := mergedJSONForSprite(withServers, spriteData, []string{"mcpServers"})
+			if err != nil || cleanup == nil {
+				t.Fatalf("expected a temporary file without the host servers, got %s, %v", path, err)
+			}
+			data, _ := os.ReadFile(path)
+			cleanup()
+			if strings.Contains(string(data), "/Users/dev") || !strings.Contains(string(data), "dark-ansi") {
+				t.Fatalf("expected the host servers left out, got %s", data)
+			}
+			if spriteData != "" && !strings.Contains(string(data), "docs.example.com") {
+				t.Fatalf("expected the sprite's own servers kept, got %s", data)
+			}
+		}
+	})
 }
 
 func TestNormalizeSpriteName(t *testing.T) {
@@ -2865,13 +3390,17 @@ func createFakeSprite(t *testing.T) (statePath, logPath string, cleanup func())
 set -e
 state="${SPRITE_STATE:-` + statePath + `}"
 log="${SPRITE_LOG:-` + logPath + `}"
-cmd="$1"
-shift || true
 logit() {
   if [ -n "$log" ]; then
     echo "$*" >> "$log"
   fi
 }
+if [ "$1" = "-o" ]; then
+  logit "org $2"
+  shift 2
+fi
+cmd="$1"
+shift || true
 case "$cmd" in
   login)
     logit "login"
@@ -2966,6 +3495,63 @@ case "$cmd" in
     logit "console $*"
     exit 0
     ;;
+  checkpoint)
+    sub="$1"
+    shift || true
+    logit "checkpoint $sub $*"
+    checkpoints="${state}.checkpoints"
+    case "$sub" in
+      create)
+        if [ "${SPRITE_FAIL_CHECKPOINT:-}" = "1" ]; then
+          exit 1
+        fi
+        comment=""
+        while [ "$#" -gt 0 ]; do
+          case "$1" in
+            --comment) comment="$2"; shift 2 ;;
+            *) shift ;;
+          esac
+        done
+        count=0
+        if [ -f "$checkpoints" ]; then
+          count="$(wc -l < "$checkpoints" | tr -d ' ')"
+        fi
+        id="v$((count + 1))"
+        while grep -q "^$id " "$checkpoints" 2>/dev/null; do
+          count=$((count + 1))
+          id="v$((count + 1))"
+        done
+        printf '%s 2026-01-01T00:00:00Z %s\n' "$id" "$comment" >> "$checkpoints"
+        echo "Created checkpoint $id"
+        ;;
+      list)
+        echo "ID CREATED COMMENT"
+        if [ -f "$checkpoints" ]; then
+          cat "$checkpoints"
+        fi
+        ;;
+      delete)
+        for arg in "$@"; do id="$arg"; done
+        if [ -f "$checkpoints" ]; then
+          grep -v "^$id " "$checkpoints" > "$checkpoints.tmp" || true
+          mv "$checkpoints.tmp" "$checkpoints"
+        fi
+        ;;
+    esac
+    exit 0
+    ;;
+  restore)
+    logit "restore $*"
+    exit 0
+    ;;
+  proxy)
+    logit "proxy $*"
+    if [ "${SPRITE_PROXY_DROP_ONCE:-}" = "1" ] && [ ! -f "${state}.proxy_dropped" ]; then
+      : > "${state}.proxy_dropped"
+      exit 1
+    fi
+    exec sleep 30
+    ;;
   exec)
     exec_args="$*"
     if [ "${SPRITE_EXEC_REQUIRE_SEPARATOR:-}" = "1" ]; then
@@ -3007,6 +3593,34 @@ case "$cmd" in
       esac
     fi
     logit "exec $exec_args"
+    if [ -n "${SPRITE_EXEC_HANG_ON:-}" ]; then
+      case "$exec_args" in
+        *"$SPRITE_EXEC_HANG_ON"*)
+          : > "${state}.hung"
+          exec sleep 30
+          ;;
+      esac
+    fi
+    if [ -n "${SPRITE_EXEC_HOME:-}" ]; then
+      # Run the command for real with SPRITE_EXEC_HOME standing in for the
+      # sprite's home directory.
+      while [ "$#" -gt 0 ] && [ "$1" != "--" ]; do
+        case "$1" in
+          -env)
+            old_ifs="$IFS"; IFS=,
+            for kv in $2; do export "$kv"; done
+            IFS="$old_ifs"
+            shift 2
+            ;;
+          -file) cp "${2%%:*}" "${2#*:}"; shift 2 ;;
+          -s) shift 2 ;;
+          *) shift ;;
+        esac
+      done
+      shift
+      cd "$SPRITE_EXEC_HOME"
+      HOME="$SPRITE_EXEC_HOME" exec "$@"
+    fi
 	if [ "${SPRITE_EXEC_HTTP_POST_NO_EXIT_FRAME:-}" = "1" ]; then
 	  case " $exec_args " in
 		*" --http-post "*garrytan/gstack*)
@@ -3160,3 +3774,10 @@ func projectRoot() string {
 		cwd = parent
 	}
 }
+
+func TestSevenUpRejectsClaudeLoginWithJSON(t *testing.T) {
+	out, err := exec.Command(testSevenBin, "up", "--no-console", "--json", "--claude-login").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "--claude-login needs a terminal") {
+		t.Fatalf("expected --claude-login with --json to be rejected, got %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"seven/backend"
+)
+
+// mcpCommandPattern is what an MCP server command must look like for seven to
+// probe it in the sprite's shell.
+var mcpCommandPattern = regexp.MustCompile(`^[A-Za-z0-9_./+-]+$`)
+
+// mcpServer is one MCP server configured on the host. Claude Code keeps its
+// servers in ~/.claude.json under "mcpServers"; Codex keeps them as
+// [mcp_servers.<name>] tables in ~/.codex/config.toml.
+type mcpServer struct {
+	Name      string
+	Assistant string // "claude" or "codex"
+	Command   string // stdio servers
+	Args      []string
+	URL       string // http and sse servers
+
+	def   map[string]interface{} // claude: the entry as the host has it
+	lines []string               // codex: the host's table and its subtables
+}
+
+// mcpOutcome is what the sync did with one host server.
+type mcpOutcome struct {
+	Server  mcpServer
+	Command string // what the sprite runs; differs from Server.Command when rewritten
+	Dropped string // why the server was left out; empty when it is live
+}
+
+func (o mcpOutcome) live() bool { return o.Dropped == "" }
+
+// unconfigurable reports that the server's assistant is not in the sprite,
+// so there is no config to write the server to.
+func (o mcpOutcome) unconfigurable() bool {
+	return o.Dropped == o.Server.Assistant+" not found in sprite"
+}
+
+// The upload paths of the assistant configs syncMCPServers rewrites.
+const (
+	mcpClaudeStage = "/tmp/seven-mcp-claude.json"
+	mcpCodexStage  = "/tmp/seven-mcp-codex.toml"
+)
+
+// syncMCPServers gives the sprite's assistants the host's MCP servers that can
+// run there. A server whose command the sprite lacks is dropped, unless the
+// host names it by an absolute path and the sprite has the same command on its
+// PATH, in which case it is rewritten to that. Servers at a host-local URL or
+// taking host paths as arguments are dropped too. It takes two sprite execs:
+// one to probe the commands and read the current configs, one to install the
+// rewritten configs. A server the repo requires (see mcpConfig) that does not
+// end up live is an error.
+func syncMCPServers(ctx context.Context, spriteName, phase string, opts upOptions) ([]mcpOutcome, error) {
+	servers := hostMCPServers(opts)
+	if len(servers) == 0 {
+		return nil, requireMCPServers(nil, opts.RequiredMCPServers)
+	}
+
+	out, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", mcpSurveyScript(servers))
+	if err != nil {
+		return nil, fmt.Errorf("inspect sprite for MCP sync: %w%s", err, gstackOutputTail(out))
+	}
+	survey := parseSyncSurvey(out)
+	home, _ := os.UserHomeDir()
+	outcomes := make([]mcpOutcome, len(servers))
+	for i, server := range servers {
+		outcomes[i] = planMCPServer(server, survey.missing, home)
+	}
+
+	var uploads []backend.File
+	var installs []syncFile
+	if data, ok, err := claudeMCPConfig(survey.existing[0], outcomes); err != nil {
+		opts.Logger(fmt.Sprintf("%s claude MCP setup failed: %v", phase, err))
+	} else if ok {
+		path, cleanup, err := writeSyncTemp("seven-mcp-*.json", data)
+		if err != nil {
+			return outcomes, err
+		}
+		defer cleanup()
+		uploads = append(uploads, backend.File{Local: path, Remote: mcpClaudeStage})
+		installs = append(installs, syncFile{Stage: mcpClaudeStage, Dest: ".claude.json", Mode: 0o600})
+	}
+	if data, ok := codexMCPConfig(survey.existing[1], outcomes); ok {
+		path, cleanup, err := writeSyncTemp("seven-mcp-*.toml", data)
+		if err != nil {
+			return outcomes, err
+		}
+		defer cleanup()
+		uploads = append(uploads, backend.File{Local: path, Remote: mcpCodexStage})
+		installs = append(installs, syncFile{Stage: mcpCodexStage, Dest: ".codex/config.toml", Mode: 0o600})
+	}
+	if len(uploads) > 0 {
+		if err := spriteExecWithFiles(ctx, spriteName, uploads, opts.QuietExternal, "sh", "-lc", syncInstallScript(installs)); err != nil {
+			return outcomes, fmt.Errorf("install MCP servers: %w", err)
+		}
+	}
+
+	var live []string
+	for _, o := range outcomes {
+		switch {
+		case !o.live():
+			opts.Logger(fmt.Sprintf("%s MCP server %s (%s) dropped: %s", phase, o.Server.Name, o.Server.Assistant, o.Dropped))
+		case o.Command != o.Server.Command:
+			opts.Logger(fmt.Sprintf("%s MCP server %s (%s) live, command rewritten to %s", phase, o.Server.Name, o.Server.Assistant, o.Command))
+		}
+		if o.live() {
+			live = append(live, o.Server.Name)
+		}
+	}
+	if len(live) == 0 {
+		live = []string{"none"}
+	}
+	opts.Logger(fmt.Sprintf("%s MCP servers live in sprite: %s", phase, strings.Join(live, ", ")))
+	return outcomes, requireMCPServers(outcomes, opts.RequiredMCPServers)
+}
+
+// requireMCPServers fails naming each required server that is not live.
+func requireMCPServers(outcomes []mcpOutcome, required []string) error {
+	var missing []string
+	for _, name := range required {
+		reason := "not configured on the host"
+		for _, o := range outcomes {
+			if o.Server.Name != name {
+				continue
+			}
+			if o.live() {
+				reason = ""
+				break
+			}
+			reason = "dropped: " + o.Dropped
+		}
+		if reason != "" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", name, reason))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required MCP servers are not live in the sprite: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// hostMCPServers lists the MCP servers of the assistants whose config the
+// user profile lets into the sprite, sorted by assistant and name.
+func hostMCPServers(opts upOptions) []mcpServer {
+	var servers []mcpServer
+	if !slices.Contains(opts.SkipCredentialSync, "claude") {
+		if p := hostHomeFile(".claude.json"); p != "" {
+			if data, err := os.ReadFile(p); err == nil {
+				servers = append(servers, parseClaudeMCPServers(data)...)
+			}
+		}
+	}
+	if !slices.Contains(opts.SkipCredentialSync, "codex") {
+		if p := hostHomeFile(".codex", "config.toml"); p != "" {
+			if data, err := os.ReadFile(p); err == nil {
+				servers = append(servers, parseCodexMCPServers(string(data))...)
+			}
+		}
+	}
+	return servers
+}
+
+func parseClaudeMCPServers(data []byte) []mcpServer {
+	var config struct {
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	var servers []mcpServer
+	for name, def := range config.MCPServers {
+		server := mcpServer{Name: name, Assistant: "claude", def: def}
+		server.Command, _ = def["command"].(string)
+		server.URL, _ = def["url"].(string)
+		if args, ok := def["args"].([]interface{}); ok {
+			for _, arg := range args {
+				if s, ok := arg.(string); ok {
+					server.Args = append(server.Args, s)
+				}
+			}
+		}
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
+}
+
+// parseCodexMCPServers reads the [mcp_servers.<name>] tables of a Codex
+// config.toml. Codex's file uses more TOML than seven's own config files, so
+// this only reads what it needs and skips lines it does not understand.
+func parseCodexMCPServers(contents string) []mcpServer {
+	var servers []mcpServer
+	var current *mcpServer // points into servers; appended to only after a new header
+	inSubtable := false
+	lines := strings.Split(contents, "\n")
+	for i := 0; i < len(lines); i++ {
+		raw := lines[i]
+		line := strings.TrimSpace(stripConfigComment(raw))
+		if strings.HasPrefix(line, "[") {
+			name, sub, ok := codexMCPTable(line)
+			switch {
+			case ok && !sub:
+				servers = append(servers, mcpServer{Name: name, Assistant: "codex"})
+				current, inSubtable = &servers[len(servers)-1], false
+			case ok && current != nil && current.Name == name:
+				inSubtable = true
+			default:
+				current = nil
+			}
+			if current != nil {
+				current.lines = append(current.lines, raw)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		current.lines = append(current.lines, raw)
+		key, value, found := strings.Cut(line, "=")
+		if !found || inSubtable {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		// A multi-line array continues until its closing bracket.
+		for strings.HasPrefix(value, "[") && !strings.Contains(value, "]") && i+1 < len(lines) {
+			i++
+			current.lines = append(current.lines, lines[i])
+			value += " " + strings.TrimSpace(stripConfigComment(lines[i]))
+		}
+		parsed, _, err := parseConfigValue(value, i+1)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "command":
+			current.Command = parsed.str
+		case "url":
+			current.URL = parsed.str
+		case "args":
+			for _, item := range parsed.list {
+				current.Args = append(current.Args, item.str)
+			}
+		}
+	}
+	sort.SliceStable(servers, func(i, j int) bool { return servers[i].Name < servers[j].Name })
+	return servers
+}
+
+// codexMCPTable recognizes a [mcp_servers.<name>] header, or one of its
+// subtables such as [mcp_servers.<name>.env]. The name may be quoted.
+func codexMCPTable(line string) (name string, sub, ok bool) {
+	if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+		return "", false, false
+	}
+	inner, found := strings.CutPrefix(strings.TrimSpace(line[1:len(line)-1]), "mcp_servers.")
+	if !found || inner == "" {
+		return "", false, false
+	}
+	rest := ""
+	if inner[0] == '"' {
+		end := strings.IndexByte(inner[1:], '"')
+		if end < 0 {
+			return "", false, false
+		}
+		name, rest = inner[1:end+1], inner[end+2:]
+	} else {
+		name, rest, _ = strings.Cut(inner, ".")
+		if rest != "" {
+			rest = "." + rest
+		}
+	}
+	return name, rest != "", name != ""
+}
+
+// mcpSurveyScript prints "missing X" for each assistant and command the
+// sprite lacks, then the sprite's ~/.claude.json and Codex config as
+// "existing 0 BASE64" and "existing 1 BASE64", in parseSyncSurvey's format.
+func mcpSurveyScript(servers []mcpServer) string {
+	var lines []string
+	probed := map[string]bool{}
+	probe := func(name, test string) {
+		if !probed[name] {
+			probed[name] = true
+			lines = append(lines, fmt.Sprintf("%s || echo 'missing %s'", test, name))
+		}
+	}
+	for _, server := range servers {
+		probe(server.Assistant, fmt.Sprintf("command -v %s >/dev/null 2>&1", server.Assistant))
+		if !mcpCommandPattern.MatchString(server.Command) {
+			continue
+		}
+		if path.IsAbs(server.Command) {
+			probe(server.Command, fmt.Sprintf("[ -x '%s' ]", server.Command))
+		}
+		if base := path.Base(server.Command); !strings.Contains(server.Command, "/") || path.IsAbs(server.Command) {
+			probe(base, fmt.Sprintf("command -v '%s' >/dev/null 2>&1", base))
+		}
+	}
+	for i, dest := range []string{".claude.json", ".codex/config.toml"} {
+		lines = append(lines, fmt.Sprintf(`if [ -s "$HOME/%s" ]; then printf 'existing %d '; base64 < "$HOME/%s" | tr -d '\n'; echo; fi`, dest, i, dest))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// planMCPServer decides whether server can run in the sprite, given the
+// assistants and commands the sprite lacks and the host's home directory.
+func planMCPServer(server mcpServer, missing map[string]bool, hostHome string) mcpOutcome {
+	o := mcpOutcome{Server: server, Command: server.Command}
+	switch {
+	case missing[server.Assistant]:
+		o.Dropped = server.Assistant + " not found in sprite"
+	case server.Command == "" && server.URL == "":
+		o.Dropped = "neither a command nor a URL"
+	case server.Command == "":
+		if u, err := url.Parse(server.URL); err != nil || u.Hostname() == "" {
+			o.Dropped = fmt.Sprintf("URL %q does not parse", server.URL)
+		} else if hostLocal(u.Hostname()) {
+			o.Dropped = fmt.Sprintf("URL %s points at the host", server.URL)
+		}
+	case !mcpCommandPattern.MatchString(server.Command):
+		o.Dropped = fmt.Sprintf("command %q is not a plain path", server.Command)
+	case !path.IsAbs(server.Command) && strings.Contains(server.Command, "/"):
+		o.Dropped = fmt.Sprintf("command %s is relative to a host directory", server.Command)
+	case path.IsAbs(server.Command) && !missing[server.Command]:
+	case path.IsAbs(server.Command) && !missing[path.Base(server.Command)]:
+		o.Command = path.Base(server.Command)
+	case missing[path.Base(server.Command)]:
+		o.Dropped = fmt.Sprintf("command %s not found in sprite", server.Command)
+	}
+	if o.live() && hostHome != "" {
+		for _, arg := range server.Args {
+			if strings.HasPrefix(arg, hostHome+"/") {
+				o.Dropped = fmt.Sprintf("argument %s is a path on the host", arg)
+				break
+			}
+		}
+	}
+	return o
+}
+
+// hostLocal reports whether host names the machine it is resolved on, which
+// inside the sprite is the sprite, not the host that configured the server.
+func hostLocal(host string) bool {
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
+
+// claudeMCPConfig updates the sprite's ~/.claude.json, spriteData, with the
+// claude outcomes: live servers are set as the host has them (with a rewritten
+// command), dropped ones are removed, and servers only the sprite has stay.
+// It reports false when there are no claude servers to apply.
+func claudeMCPConfig(spriteData string, outcomes []mcpOutcome) ([]byte, bool, error) {
+	config := map[string]interface{}{}
+	if strings.TrimSpace(spriteData) != "" {
+		if err := json.Unmarshal([]byte(spriteData), &config); err != nil {
+			return nil, false, fmt.Errorf("sprite ~/.claude.json is not valid JSON: %w", err)
+		}
+	}
+	servers, _ := config["mcpServers"].(map[string]interface{})
+	if servers == nil {
+		servers = map[string]interface{}{}
+	}
+	changed := false
+	for _, o := range outcomes {
+		if o.Server.Assistant != "claude" || o.unconfigurable() {
+			continue
+		}
+		changed = true
+		if !o.live() {
+			delete(servers, o.Server.Name)
+			continue
+		}
+		def := map[string]interface{}{}
+		for k, v := range o.Server.def {
+			def[k] = v
+		}
+		if o.Command != "" {
+			def["command"] = o.Command
+		}
+		servers[o.Server.Name] = def
+	}
+	if !changed {
+		return nil, false, nil
+	}
+	config["mcpServers"] = servers
+	data, err := json.MarshalIndent(config, "", "  ")
+	return data, err == nil, err
+}
+
+// codexMCPConfig rewrites the sprite's Codex config, spriteData, with the
+// codex outcomes: every table of a host server is removed, then the live ones
+// are appended as the host has them (with a rewritten command). It reports
+// false when there are no codex servers to apply.
+func codexMCPConfig(spriteData string, outcomes []mcpOutcome) ([]byte, bool) {
+	names := map[string]bool{}
+	for _, o := range outcomes {
+		if o.Server.Assistant == "codex" && !o.unconfigurable() {
+			names[o.Server.Name] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, false
+	}
+
+	var kept []string
+	skipping := false
+	for _, raw := range strings.Split(strings.TrimRight(spriteData, "\n"), "\n") {
+		line := strings.TrimSpace(stripConfigComment(raw))
+		if strings.HasPrefix(line, "[") {
+			name, _, ok := codexMCPTable(line)
+			skipping = ok && names[name]
+		}
+		if !skipping {
+			kept = append(kept, raw)
+		}
+	}
+	text := strings.TrimRight(strings.Join(kept, "\n"), "\n")
+	for _, o := range outcomes {
+		if o.Server.Assistant != "codex" || !o.live() {
+			continue
+		}
+		if text != "" {
+			text += "\n\n"
+		}
+		lines := slices.Clone(o.Server.lines)
+		for i, raw := range lines {
+			if key, _, found := strings.Cut(strings.TrimSpace(raw), "="); found && strings.TrimSpace(key) == "command" && o.Command != o.Server.Command {
+				lines[i] = fmt.Sprintf("command = %q", o.Command)
+				break
+			}
+		}
+		text += strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	}
+	return []byte(text + "\n"), true
+}
+
+// writeSyncTemp writes data to a 0600 temporary file for upload and returns
+// a cleanup func that removes it.
+func writeSyncTemp(pattern string, data []byte) (string, func(), error) {
+	tmpFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() { os.Remove(tmpFile.Name()) }
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		remove()
+		return "", nil, err
+	}
+	if err := tmpFile.Close(); err != nil {
+		remove()
+		return "", nil, err
+	}
+	return tmpFile.Name(), remove, nil
+}
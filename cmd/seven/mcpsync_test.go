@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"seven/backend/fake"
+)
+
+const testCodexConfig = `model = "o3"
+
+[mcp_servers.github]
+command = "/opt/homebrew/bin/github-mcp" # host install
+args = [
+  "stdio",
+  "--read-only",
+]
+
+[mcp_servers.github.env]
+GITHUB_TOKEN = "x"
+
+[mcp_servers."docs.site"]
+url = "http://localhost:8080/mcp"
+
+[profiles.fast]
+model = "o4-mini"
+`
+
+func TestParseCodexMCPServers(t *testing.T) {
+	servers := parseCodexMCPServers(testCodexConfig)
+	if len(servers) != 2 {
+		t.Fatalf("expected two servers, got %+v", servers)
+	}
+	docs, github := servers[0], servers[1]
+	if docs.Name != "docs.site" || docs.URL != "http://localhost:8080/mcp" {
+		t.Fatalf("unexpected url server: %+v", docs)
+	}
+	if github.Command != "/opt/homebrew/bin/github-mcp" || !slices.Equal(github.Args, []string{"stdio", "--read-only"}) {
+		t.Fatalf("unexpected stdio server: %+v", github)
+	}
+	if joined := strings.Join(github.lines, "\n"); !strings.Contains(joined, "GITHUB_TOKEN") || strings.Contains(joined, "o4-mini") {
+		t.Fatalf("expected the server's table and env subtable only, got:\n%s", joined)
+	}
+}
+
+func TestPlanMCPServer(t *testing.T) {
+	missing := map[string]bool{"codex": true, "/opt/homebrew/bin/github-mcp": true, "uvx": true}
+	cases := map[string]struct {
+		server  mcpServer
+		command string
+		dropped string
+	}{
+		"assistant missing":  {server: mcpServer{Assistant: "codex", Command: "npx"}, command: "npx", dropped: "codex not found in sprite"},
+		"on path":            {server: mcpServer{Assistant: "claude", Command: "npx"}, command: "npx"},
+		"rewritten":          {server: mcpServer{Assistant: "claude", Command: "/opt/homebrew/bin/github-mcp"}, command: "github-mcp"},
+		"command missing":    {server: mcpServer{Assistant: "claude", Command: "uvx"}, command: "uvx", dropped: "command uvx not found in sprite"},
+		"relative command":   {server: mcpServer{Assistant: "claude", Command: "./bin/mcp"}, command: "./bin/mcp", dropped: "command ./bin/mcp is relative to a host directory"},
+		"host path argument": {server: mcpServer{Assistant: "claude", Command: "node", Args: []string{"/Users/dev/mcp/index.js"}}, command: "node", dropped: "argument /Users/dev/mcp/index.js is a path on the host"},
+		"localhost url":      {server: mcpServer{Assistant: "claude", URL: "http://127.0.0.1:3845/mcp"}, dropped: "URL http://127.0.0.1:3845/mcp points at the host"},
+		"remote url":         {server: mcpServer{Assistant: "claude", URL: "https://mcp.example.com/sse"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := planMCPServer(tc.server, missing, "/Users/dev")
+			if got.Command != tc.command || got.Dropped != tc.dropped {
+				t.Fatalf("got command %q dropped %q; want %q, %q", got.Command, got.Dropped, tc.command, tc.dropped)
+			}
+		})
+	}
+}
+
+func TestCodexMCPConfigReplacesHostTables(t *testing.T) {
+	servers := parseCodexMCPServers(testCodexConfig)
+	outcomes := []mcpOutcome{
+		{Server: servers[0], Dropped: "URL http://localhost:8080/mcp points at the host"},
+		{Server: servers[1], Command: "github-mcp"},
+	}
+	spriteConfig := testCodexConfig + "\n[mcp_servers.sprite-only]\ncommand = \"true\"\n"
+	data, ok := codexMCPConfig(spriteConfig, outcomes)
+	if !ok {
+		t.Fatal("expected a rewritten config")
+	}
+	got := string(data)
+	for _, want := range []string{`model = "o3"`, "[profiles.fast]", "[mcp_servers.sprite-only]", `command = "github-mcp"`, "GITHUB_TOKEN"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "docs.site") || strings.Contains(got, "/opt/homebrew") || strings.Count(got, "[mcp_servers.github]") != 1 {
+		t.Fatalf("expected the dropped server gone and github once, got:\n%s", got)
+	}
+}
+
+func TestSyncMCPServersInstallsLiveServersAndChecksRequired(t *testing.T) {
+	isolateHostForInit(t)
+	home, _ := os.UserHomeDir()
+	claudeJSON := `{"mcpServers":{
+		"github":{"command":"/opt/homebrew/bin/github-mcp","args":["stdio"],"env":{"GITHUB_TOKEN":"x"}},
+		"figma":{"type":"http","url":"http://127.0.0.1:3845/mcp"}
+	}}`
+	if err := os.WriteFile(filepath.Join(home, ".claude.json"), []byte(claudeJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	existing := base64.StdEncoding.EncodeToString([]byte(`{"projects":{},"mcpServers":{"figma":{},"mine":{"command":"true"}}}`))
+	b.OnExec(fake.CommandContains("echo 'missing claude'"), fake.Reply{Stdout: "missing /opt/homebrew/bin/github-mcp\nexisting 0 " + existing + "\n"})
+
+	var logs []string
+	opts := upOptions{QuietExternal: true, RequiredMCPServers: []string{"github", "figma"}, Logger: func(msg string) { logs = append(logs, msg) }}
+	outcomes, err := syncMCPServers(context.Background(), "hello", "[seven init]", opts)
+	if err == nil || !strings.Contains(err.Error(), "figma (dropped: URL http://127.0.0.1:3845/mcp points at the host)") || strings.Contains(err.Error(), "github") {
+		t.Fatalf("expected only figma to be reported missing, got %v", err)
+	}
+	if got := mcpSyncResult(outcomes); got != "live: github; dropped: figma (URL http://127.0.0.1:3845/mcp points at the host)" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+
+	sprite, _ := b.Sprite("hello")
+	var config struct {
+		Projects   map[string]interface{}            `json:"projects"`
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(sprite.Files[mcpClaudeStage].Contents, &config); err != nil {
+		t.Fatalf("uploaded claude config is not JSON: %v", err)
+	}
+	if config.Projects == nil || config.MCPServers["mine"] == nil || config.MCPServers["figma"] != nil {
+		t.Fatalf("expected sprite keys kept and figma removed, got %+v", config)
+	}
+	if github := config.MCPServers["github"]; github["command"] != "github-mcp" || github["env"] == nil {
+		t.Fatalf("expected github rewritten with its env, got %v", github)
+	}
+	if joined := strings.Join(logs, "\n"); !strings.Contains(joined, "MCP servers live in sprite: github") {
+		t.Fatalf("expected the live servers to be reported, got:\n%s", joined)
+	}
+}
+
+func TestSyncMCPServersWithoutHostServers(t *testing.T) {
+	isolateHostForInit(t)
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+
+	outcomes, err := syncMCPServers(context.Background(), "hello", "[seven init]", upOptions{RequiredMCPServers: []string{"github"}, Logger: func(string) {}})
+	if len(outcomes) != 0 || err == nil || !strings.Contains(err.Error(), "github (not configured on the host)") {
+		t.Fatalf("expected the required server to be reported, got %v, %v", outcomes, err)
+	}
+	if execs := b.Execs(); len(execs) != 0 {
+		t.Fatalf("expected no sprite execs without host servers, got %q", execs)
+	}
+}
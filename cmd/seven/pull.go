@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"seven/backend"
+)
+
+// pullBundleMarker starts the stdout line carrying the bundle digest. Login
+// shell profiles may print their own lines first; everything before the
+// marker is ignored.
+const pullBundleMarker = "SEVEN_BUNDLE_SHA256"
+
+// pullBundleScript bundles the sprite repo's branches (or the single branch in
+// $2) from $HOME/$1 and writes the bundle's SHA-256 on a line starting with
+// pullBundleMarker, followed by the bundle itself in base64. The bundle never
+// leaves the sprite's temp directory otherwise.
+const pullBundleScript = `set -eu
+repo="$HOME/$1"
+if ! cd "$repo" 2>/dev/null; then
+  echo "$repo not found in the sprite (run 'seven up' first)" >&2
+  exit 1
+fi
+if [ -n "$2" ]; then
+  if ! git rev-parse --verify -q "refs/heads/$2" >/dev/null; then
+    echo "branch $2 not found in the sprite repo" >&2
+    exit 1
+  fi
+  set -- "refs/heads/$2"
+else
+  set -- --branches
+fi
+bundle="$(mktemp)"
+trap 'rm -f "$bundle"' EXIT
+git bundle create "$bundle" "$@" >&2
+printf '` + pullBundleMarker + ` %s\n' "$(sha256sum "$bundle" | cut -d' ' -f1)"
+base64 < "$bundle"`
+
+func cmdPull(args []string) {
+	fs := flag.NewFlagSet("pull", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "pull from a specific sprite name")
+	branch := fs.String("branch", "", "pull only this sprite branch")
+
+	ordinal, args := parseSpriteOrdinal("pull", args)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "seven pull failed: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+	if ordinal > 0 && strings.TrimSpace(*spriteName) != "" {
+		fmt.Fprintln(os.Stderr, "seven pull failed: sprite number cannot be combined with --sprite")
+		os.Exit(1)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	*branch = strings.TrimSpace(*branch)
+	if *branch != "" {
+		if _, err := runCmdOutput(ctx, "git", nil, "check-ref-format", "--branch", *branch); err != nil {
+			fmt.Fprintf(os.Stderr, "seven pull failed: invalid branch %q\n", *branch)
+			os.Exit(1)
+		}
+	}
+	if inside, err := runCmdOutput(ctx, "git", nil, "rev-parse", "--is-inside-work-tree"); err != nil || inside != "true" {
+		fmt.Fprintln(os.Stderr, "seven pull failed: run it inside the host git checkout")
+		os.Exit(1)
+	}
+
+	name := resolveExistingSprite(ctx, "pull", upOptions{SpriteName: strings.TrimSpace(*spriteName), SiblingOrdinal: ordinal})
+	logger := func(msg string) { fmt.Println(formatStyledBulletLog(msg)) }
+	if err := pullFromSprite(ctx, name, *branch, logger); err != nil {
+		fmt.Fprintf(os.Stderr, "seven pull failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// pullFromSprite fetches the sprite repo's branches into
+// refs/remotes/sprite-<name>/* of the host checkout in the current directory.
+// The bundle is checked against the digest computed in the sprite and with
+// `git bundle verify` before anything is fetched.
+func pullFromSprite(ctx context.Context, spriteName, branch string, logger func(string)) error {
+	repoDir := spriteFamilyBase(spriteName)
+	logger(fmt.Sprintf("[seven pull] bundling ~/%s in %s", repoDir, spriteName))
+	bundle, err := fetchSpriteBundle(ctx, spriteName, repoDir, branch)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", "seven-pull-*.bundle")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(bundle); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if out, err := runCmdOutput(ctx, "git", nil, "bundle", "verify", file.Name()); err != nil {
+		return fmt.Errorf("bundle verification failed: %v%s", err, gstackOutputTail(out))
+	}
+
+	remote := "sprite-" + spriteName
+	refspec := "+refs/heads/*:refs/remotes/" + remote + "/*"
+	if branch != "" {
+		refspec = "+refs/heads/" + branch + ":refs/remotes/" + remote + "/" + branch
+	}
+	if err := runCmd(ctx, "git", nil, "fetch", "--no-tags", file.Name(), refspec); err != nil {
+		return fmt.Errorf("git fetch from bundle failed: %w", err)
+	}
+	logger(fmt.Sprintf("[seven pull] fetched %s into refs/remotes/%s/", bundleSummary(branch), remote))
+	return nil
+}
+
+// fetchSpriteBundle streams a bundle out of the sprite over exec stdout and
+// returns it once its SHA-256 matches the digest the sprite reported.
+func fetchSpriteBundle(ctx context.Context, spriteName, repoDir, branch string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	err := activeBackend.Exec(ctx, spriteName, backend.ExecRequest{
+		Command: []string{"sh", "-lc", pullBundleScript, "seven-pull", repoDir, branch},
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bundle in sprite: %v%s", err, gstackOutputTail(stderr.String()))
+	}
+	out := stdout.String()
+	start := strings.Index("\n"+out, "\n"+pullBundleMarker+" ")
+	if start < 0 {
+		return nil, fmt.Errorf("sprite did not report a bundle digest")
+	}
+	line, encoded, _ := strings.Cut(out[start:], "\n")
+	digest := strings.TrimSpace(strings.TrimPrefix(line, pullBundleMarker+" "))
+	if !sha256Pattern.MatchString(digest) {
+		return nil, fmt.Errorf("sprite did not report a bundle digest")
+	}
+	bundle, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	sum := sha256.Sum256(bundle)
+	if hex.EncodeToString(sum[:]) != digest {
+		return nil, errors.New("bundle digest mismatch; the transfer was corrupted")
+	}
+	return bundle, nil
+}
+
+func bundleSummary(branch string) string {
+	if branch != "" {
+		return "branch " + branch
+	}
+	return "all branches"
+}
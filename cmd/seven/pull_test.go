@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitTestCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Seven Tests",
+		"GIT_AUTHOR_EMAIL=seven-tests@example.com",
+		"GIT_COMMITTER_NAME=Seven Tests",
+		"GIT_COMMITTER_EMAIL=seven-tests@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// cloneIntoSpriteHome clones repo to <spriteHome>/hello, the layout seven init
+// creates, and commits on a new agent branch there.
+func cloneIntoSpriteHome(t *testing.T, repo, spriteHome string) string {
+	t.Helper()
+	gitTestCmd(t, spriteHome, "clone", "-q", repo, "hello")
+	clone := filepath.Join(spriteHome, "hello")
+	gitTestCmd(t, clone, "checkout", "-q", "-b", "agent/fix")
+	if err := os.WriteFile(filepath.Join(clone, "fix.txt"), []byte("fixed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitTestCmd(t, clone, "add", "fix.txt")
+	gitTestCmd(t, clone, "commit", "-q", "-m", "agent fix")
+	return gitTestCmd(t, clone, "rev-parse", "HEAD")
+}
+
+func runSevenPull(t *testing.T, repo, state, logPath, spriteHome string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, append([]string{"pull"}, args...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_EXEC_HOME="+spriteHome,
+	)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestSevenPullFetchesSpriteBranches(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome := t.TempDir()
+	agentHead := cloneIntoSpriteHome(t, repo, spriteHome)
+
+	out, err := runSevenPull(t, repo, state, logPath, spriteHome)
+	if err != nil {
+		t.Fatalf("seven pull failed: %v\n%s", err, out)
+	}
+	if got := gitTestCmd(t, repo, "rev-parse", "refs/remotes/sprite-hello/agent/fix"); got != agentHead {
+		t.Fatalf("expected sprite-hello/agent/fix at %s, got %s", agentHead, got)
+	}
+	if _, err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "-q", "refs/heads/agent/fix").Output(); err == nil {
+		t.Fatalf("seven pull must not create local branches")
+	}
+}
+
+func TestSevenPullSingleBranch(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome := t.TempDir()
+	agentHead := cloneIntoSpriteHome(t, repo, spriteHome)
+
+	out, err := runSevenPull(t, repo, state, logPath, spriteHome, "--branch", "agent/fix")
+	if err != nil {
+		t.Fatalf("seven pull --branch failed: %v\n%s", err, out)
+	}
+	refs := gitTestCmd(t, repo, "for-each-ref", "--format=%(refname) %(objectname)", "refs/remotes/sprite-hello/")
+	if refs != "refs/remotes/sprite-hello/agent/fix "+agentHead {
+		t.Fatalf("expected only the requested branch, got: %s", refs)
+	}
+
+	out, err = runSevenPull(t, repo, state, logPath, spriteHome, "--branch", "missing")
+	if err == nil || !strings.Contains(out, "branch missing not found") {
+		t.Fatalf("expected missing branch to fail, err=%v output=%s", err, out)
+	}
+}
+
+func TestSevenPullIgnoresProfileOutput(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome := t.TempDir()
+	cloneIntoSpriteHome(t, repo, spriteHome)
+	if err := os.WriteFile(filepath.Join(spriteHome, ".profile"), []byte("echo 'Welcome to your sprite'\necho "+strings.Repeat("f", 64)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runSevenPull(t, repo, state, logPath, spriteHome)
+	if err != nil || !strings.Contains(out, "fetched all branches") {
+		t.Fatalf("expected profile output before the digest to be ignored, err=%v output=%s", err, out)
+	}
+}
+
+func TestSevenPullRejectsCorruptTransfer(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome := t.TempDir()
+	cloneIntoSpriteHome(t, repo, spriteHome)
+	// A sha256sum that reports a different digest stands in for corruption in transit.
+	fakeBin := filepath.Join(spriteHome, "bin")
+	if err := os.MkdirAll(fakeBin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeExecutable(t, filepath.Join(fakeBin, "sha256sum"), "#!/bin/sh\necho "+strings.Repeat("0", 64)+"  \"$1\"\n")
+	if err := os.WriteFile(filepath.Join(spriteHome, ".profile"), []byte("PATH=\""+fakeBin+":$PATH\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runSevenPull(t, repo, state, logPath, spriteHome)
+	if err == nil || !strings.Contains(out, "bundle digest mismatch") {
+		t.Fatalf("expected corrupt bundle to be rejected, err=%v output=%s", err, out)
+	}
+	if refs := gitTestCmd(t, repo, "for-each-ref", "refs/remotes/sprite-hello/"); refs != "" {
+		t.Fatalf("nothing may be fetched from an unverified bundle, got: %s", refs)
+	}
+}
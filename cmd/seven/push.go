@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"seven/backend"
+)
+
+var gitObjectPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// pushApplyScript seeds the sprite repo ($HOME/$1) with host work. Arguments:
+// branch, host HEAD, expected tree, uploaded bundle path (or ""), uploaded
+// patch path (or ""), and "1" to allow replacing a diverged branch. It refuses
+// to touch a dirty sprite checkout, and on any failure after switching branches
+// it restores the previous branch and checkout so nothing is half-applied.
+const pushApplyScript = `set -eu
+repo="$HOME/$1"; branch="$2"; head="$3"; want_tree="$4"; bundle="$5"; patch="$6"; force="$7"
+trap 'rm -f "$bundle" "$patch"' EXIT
+if ! cd "$repo" 2>/dev/null; then
+  echo "$repo not found in the sprite (run 'seven up' first)" >&2
+  exit 1
+fi
+if [ -n "$(git status --porcelain --untracked-files=normal)" ]; then
+  echo "sprite repo has uncommitted changes; commit or stash them in the sprite first" >&2
+  exit 1
+fi
+if [ -n "$bundle" ]; then
+  git bundle verify -q "$bundle" >&2
+  git fetch -q --no-tags "$bundle" HEAD
+fi
+if ! git cat-file -e "$head^{commit}" 2>/dev/null; then
+  echo "host commit $head is not available in the sprite" >&2
+  exit 1
+fi
+existing="$(git rev-parse -q --verify "refs/heads/$branch" || true)"
+if [ -n "$existing" ] && [ "$force" != "1" ] && ! git merge-base --is-ancestor "$existing" "$head"; then
+  echo "branch $branch in the sprite has commits the host does not; pass --force to replace it" >&2
+  exit 1
+fi
+prev="$(git symbolic-ref -q --short HEAD || git rev-parse HEAD)"
+fail() {
+  echo "$1" >&2
+  git reset -q --hard
+  git checkout -q --detach
+  if [ -n "$existing" ]; then
+    git update-ref "refs/heads/$branch" "$existing"
+  else
+    git branch -q -D "$branch"
+  fi
+  git checkout -q "$prev"
+  exit 1
+}
+git checkout -q -B "$branch" "$head"
+if [ -n "$patch" ]; then
+  git apply --index "$patch" || fail "uncommitted changes did not apply"
+fi
+tree="$(git write-tree)" || fail "could not compute the sprite tree"
+if [ "$tree" != "$want_tree" ]; then
+  fail "sprite tree $tree does not match host tree $want_tree"
+fi
+git reset -q
+echo "$tree"`
+
+func cmdPush(args []string) {
+	fs := flag.NewFlagSet("push", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "push to a specific sprite name")
+	branch := fs.String("branch", "", "sprite branch to create (default: the host branch)")
+	force := fs.Bool("force", false, "replace a sprite branch that has diverged from the host")
+
+	ordinal, args := parseSpriteOrdinal("push", args)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "seven push failed: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+	if ordinal > 0 && strings.TrimSpace(*spriteName) != "" {
+		fmt.Fprintln(os.Stderr, "seven push failed: sprite number cannot be combined with --sprite")
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	// Resolve the sprite first: it exits on failure, which would skip the
+	// snapshot's deferred cleanup and leave a copy of the WIP in $TMPDIR.
+	name := resolveExistingSprite(ctx, "push", upOptions{SpriteName: strings.TrimSpace(*spriteName), SiblingOrdinal: ordinal})
+	snapshot, err := snapshotHostWork(ctx, strings.TrimSpace(*branch))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven push failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer snapshot.cleanup()
+
+	logger := func(msg string) { fmt.Println(formatStyledBulletLog(msg)) }
+	if err := pushToSprite(ctx, name, snapshot, *force, logger); err != nil {
+		snapshot.cleanup()
+		fmt.Fprintf(os.Stderr, "seven push failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// hostSnapshot is the host checkout's committed HEAD plus the tree of its
+// working directory, including untracked files that are not ignored.
+type hostSnapshot struct {
+	top    string
+	branch string
+	head   string
+	tree   string
+	patch  string // path of a binary diff from head to tree, or "" when clean
+	tmpDir string
+}
+
+func (s hostSnapshot) cleanup() {
+	if s.tmpDir != "" {
+		_ = os.RemoveAll(s.tmpDir)
+	}
+}
+
+// snapshotHostWork records the working directory as a tree object using a
+// throwaway index, so the user's real index and stash are left untouched.
+func snapshotHostWork(ctx context.Context, branch string) (hostSnapshot, error) {
+	top, err := runCmdOutput(ctx, "git", nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return hostSnapshot{}, fmt.Errorf("run it inside the host git checkout")
+	}
+	s := hostSnapshot{top: top, branch: branch}
+	if s.head, err = runCmdOutput(ctx, "git", nil, "-C", top, "rev-parse", "--verify", "HEAD^{commit}"); err != nil || !gitObjectPattern.MatchString(s.head) {
+		return hostSnapshot{}, fmt.Errorf("host checkout has no commits")
+	}
+	if s.branch == "" {
+		if current, err := runCmdOutput(ctx, "git", nil, "-C", top, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+			s.branch = current
+		} else {
+			s.branch = "seven/host-" + s.head[:12]
+		}
+	}
+	if _, err := runCmdOutput(ctx, "git", nil, "check-ref-format", "--branch", s.branch); err != nil {
+		return hostSnapshot{}, fmt.Errorf("invalid branch %q", s.branch)
+	}
+
+	if s.tmpDir, err = os.MkdirTemp("", "seven-push-"); err != nil {
+		return hostSnapshot{}, err
+	}
+	indexEnv := []string{"GIT_INDEX_FILE=" + filepath.Join(s.tmpDir, "index")}
+	for _, args := range [][]string{
+		{"-C", top, "read-tree", "HEAD"},
+		{"-C", top, "add", "-A", "--", ".", ":(exclude).sprite"},
+	} {
+		if out, err := runCmdOutput(ctx, "git", indexEnv, args...); err != nil {
+			s.cleanup()
+			return hostSnapshot{}, fmt.Errorf("snapshot host working tree: %v%s", err, gstackOutputTail(out))
+		}
+	}
+	if s.tree, err = runCmdOutput(ctx, "git", indexEnv, "-C", top, "write-tree"); err != nil || !gitObjectPattern.MatchString(s.tree) {
+		s.cleanup()
+		return hostSnapshot{}, fmt.Errorf("snapshot host working tree: %v", err)
+	}
+
+	// Patches must keep their exact bytes, so bypass runCmdOutput's trimming.
+	patch, err := commandContext(ctx, "git", "-C", top, "diff", "--binary", "--full-index", "--no-renames", s.head, s.tree).Output()
+	if err != nil {
+		s.cleanup()
+		return hostSnapshot{}, fmt.Errorf("diff host working tree: %w", err)
+	}
+	if len(patch) > 0 {
+		s.patch = filepath.Join(s.tmpDir, "wip.patch")
+		if err := os.WriteFile(s.patch, patch, 0o600); err != nil {
+			s.cleanup()
+			return hostSnapshot{}, err
+		}
+	}
+	return s, nil
+}
+
+// pushToSprite uploads the commits the sprite lacks as a bundle and the
+// uncommitted changes as a patch, applies both on the target branch, and
+// requires the sprite's resulting tree to equal the host's.
+func pushToSprite(ctx context.Context, spriteName string, s hostSnapshot, force bool, logger func(string)) error {
+	repoDir := spriteFamilyBase(spriteName)
+	known, err := spriteExecOutput(ctx, spriteName, nil, "sh", "-lc", `cd "$HOME/$1" && git for-each-ref --format='%(objectname)'`, "seven-push", repoDir)
+	if err != nil {
+		return fmt.Errorf("read sprite refs: %v%s", err, gstackOutputTail(known))
+	}
+
+	// Exclude history the sprite already has, so only unpushed commits travel.
+	var shared []string
+	for _, sha := range strings.Fields(known) {
+		if gitObjectPattern.MatchString(sha) && commandContext(ctx, "git", "-C", s.top, "cat-file", "-e", sha+"^{commit}").Run() == nil {
+			shared = append(shared, sha)
+		}
+	}
+	revs := append([]string{"HEAD", "--not"}, shared...)
+	commits, err := runCmdOutput(ctx, "git", nil, append([]string{"-C", s.top, "rev-list", "--count"}, revs...)...)
+	if err != nil {
+		return fmt.Errorf("count unpushed commits: %v", err)
+	}
+
+	var files []backend.File
+	remoteBundle, remotePatch := "", ""
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	if commits != "0" {
+		bundleArgs := append([]string{"-C", s.top, "bundle", "create", filepath.Join(s.tmpDir, "host.bundle")}, revs...)
+		if out, err := runCmdOutput(ctx, "git", nil, bundleArgs...); err != nil {
+			return fmt.Errorf("bundle host commits: %v%s", err, gstackOutputTail(out))
+		}
+		remoteBundle = "/tmp/seven-push-" + token + ".bundle"
+		files = append(files, backend.File{Local: filepath.Join(s.tmpDir, "host.bundle"), Remote: remoteBundle})
+	}
+	if s.patch != "" {
+		remotePatch = "/tmp/seven-push-" + token + ".patch"
+		files = append(files, backend.File{Local: s.patch, Remote: remotePatch})
+	}
+
+	logger(fmt.Sprintf("[seven push] seeding %s in %s with %s commit(s) and %s", s.branch, spriteName, commits, wipSummary(s.patch)))
+	forceArg := ""
+	if force {
+		forceArg = "1"
+	}
+	var stdout, stderr strings.Builder
+	err = activeBackend.Exec(ctx, spriteName, backend.ExecRequest{
+		Command: []string{"sh", "-lc", pushApplyScript, "seven-push", repoDir, s.branch, s.head, s.tree, remoteBundle, remotePatch, forceArg},
+		Files:   files,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("apply in sprite: %v%s", err, gstackOutputTail(stderr.String()))
+	}
+	if got := strings.TrimSpace(stdout.String()); got != s.tree {
+		return fmt.Errorf("sprite reported tree %q, expected host tree %s", got, s.tree)
+	}
+	logger(fmt.Sprintf("[seven push] %s in %s matches the host working tree (%s)", s.branch, spriteName, s.tree[:12]))
+	return nil
+}
+
+func wipSummary(patch string) string {
+	if patch == "" {
+		return "no uncommitted changes"
+	}
+	return "uncommitted changes"
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runSevenPush(t *testing.T, repo, state, logPath, spriteHome string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(testSevenBin, append([]string{"push"}, args...)...)
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_EXEC_HOME="+spriteHome,
+	)
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// setupPushFixture returns a host repo whose sprite clone (at <spriteHome>/hello)
+// predates one local commit, plus a modified and an untracked file on the host.
+func setupPushFixture(t *testing.T) (repo, state, logPath, spriteHome string) {
+	t.Helper()
+	repo = createTempRepo(t)
+	var cleanup func()
+	state, logPath, cleanup = createFakeSprite(t)
+	t.Cleanup(cleanup)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	if err := os.WriteFile(state, []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write state: %v", err)
+	}
+	spriteHome = t.TempDir()
+	gitTestCmd(t, spriteHome, "clone", "-q", repo, "hello")
+
+	gitTestCmd(t, repo, "checkout", "-q", "-b", "wip")
+	if err := os.WriteFile(filepath.Join(repo, "local.txt"), []byte("unpushed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitTestCmd(t, repo, "add", "local.txt")
+	gitTestCmd(t, repo, "commit", "-q", "-m", "unpushed commit")
+	if err := os.WriteFile(filepath.Join(repo, "README.md"), []byte("fixture\nedited \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "new.sh"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return repo, state, logPath, spriteHome
+}
+
+func TestSevenPushSeedsBranchWithHostWork(t *testing.T) {
+	repo, state, logPath, spriteHome := setupPushFixture(t)
+
+	out, err := runSevenPush(t, repo, state, logPath, spriteHome)
+	if err != nil {
+		t.Fatalf("seven push failed: %v\n%s", err, out)
+	}
+	clone := filepath.Join(spriteHome, "hello")
+	if got, want := gitTestCmd(t, clone, "rev-parse", "HEAD"), gitTestCmd(t, repo, "rev-parse", "HEAD"); got != want {
+		t.Fatalf("expected sprite HEAD %s, got %s", want, got)
+	}
+	if branch := gitTestCmd(t, clone, "symbolic-ref", "--short", "HEAD"); branch != "wip" {
+		t.Fatalf("expected the host branch name in the sprite, got %q", branch)
+	}
+	if data, _ := os.ReadFile(filepath.Join(clone, "README.md")); string(data) != "fixture\nedited \n" {
+		t.Fatalf("expected uncommitted edit in the sprite, got %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(clone, "new.sh")); err != nil || info.Mode()&0o111 == 0 {
+		t.Fatalf("expected untracked executable to arrive, info=%v err=%v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(clone, ".sprite")); !os.IsNotExist(err) {
+		t.Fatalf(".sprite must not be pushed, stat err=%v", err)
+	}
+	if status := gitTestCmd(t, clone, "status", "--porcelain"); !strings.Contains(status, "M README.md") || !strings.Contains(status, "?? new.sh") {
+		t.Fatalf("expected uncommitted work to stay uncommitted in the sprite, got:\n%s", status)
+	}
+	if status := gitTestCmd(t, repo, "status", "--porcelain"); !strings.Contains(status, "?? new.sh") {
+		t.Fatalf("the host index must be left untouched, got:\n%s", status)
+	}
+	if !strings.Contains(out, "matches the host working tree") {
+		t.Fatalf("expected tree verification in output, got: %s", out)
+	}
+}
+
+func TestSevenPushRollsBackOnTreeMismatch(t *testing.T) {
+	repo, state, logPath, spriteHome := setupPushFixture(t)
+	// Rewriting whitespace while applying changes the resulting tree.
+	if err := os.WriteFile(filepath.Join(spriteHome, ".gitconfig"), []byte("[apply]\n\twhitespace = fix\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runSevenPush(t, repo, state, logPath, spriteHome)
+	if err == nil || !strings.Contains(out, "does not match host tree") {
+		t.Fatalf("expected tree mismatch to fail closed, err=%v output=%s", err, out)
+	}
+	clone := filepath.Join(spriteHome, "hello")
+	if branch := gitTestCmd(t, clone, "symbolic-ref", "--short", "HEAD"); branch == "wip" {
+		t.Fatalf("expected the previous branch to be restored")
+	}
+	if refs := gitTestCmd(t, clone, "branch", "--list", "wip"); refs != "" {
+		t.Fatalf("expected the new branch to be removed, got %q", refs)
+	}
+	if status := gitTestCmd(t, clone, "status", "--porcelain"); status != "" {
+		t.Fatalf("expected a clean sprite checkout after rollback, got:\n%s", status)
+	}
+}
+
+func TestSevenPushRefusesToClobberSpriteWork(t *testing.T) {
+	repo, state, logPath, spriteHome := setupPushFixture(t)
+	clone := filepath.Join(spriteHome, "hello")
+
+	if err := os.WriteFile(filepath.Join(clone, "agent.txt"), []byte("agent wip\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := runSevenPush(t, repo, state, logPath, spriteHome)
+	if err == nil || !strings.Contains(out, "sprite repo has uncommitted changes") {
+		t.Fatalf("expected dirty sprite checkout to be refused, err=%v output=%s", err, out)
+	}
+
+	gitTestCmd(t, clone, "checkout", "-q", "-b", "wip")
+	gitTestCmd(t, clone, "add", "agent.txt")
+	gitTestCmd(t, clone, "commit", "-q", "-m", "agent commit")
+	agentHead := gitTestCmd(t, clone, "rev-parse", "HEAD")
+	out, err = runSevenPush(t, repo, state, logPath, spriteHome)
+	if err == nil || !strings.Contains(out, "pass --force") {
+		t.Fatalf("expected diverged sprite branch to be refused, err=%v output=%s", err, out)
+	}
+	if got := gitTestCmd(t, clone, "rev-parse", "wip"); got != agentHead {
+		t.Fatalf("refused push must not move the sprite branch, got %s", got)
+	}
+
+	out, err = runSevenPush(t, repo, state, logPath, spriteHome, "--force")
+	if err != nil {
+		t.Fatalf("seven push --force failed: %v\n%s", err, out)
+	}
+}
+
+func TestSevenPushLeavesNoSnapshotWhenSpriteIsMissing(t *testing.T) {
+	repo, state, logPath, spriteHome := setupPushFixture(t)
+	if err := os.WriteFile(state, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmp := t.TempDir()
+	cmd := exec.Command(testSevenBin, "push")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+		"SPRITE_EXEC_HOME="+spriteHome,
+		"TMPDIR="+tmp,
+	)
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected push to a missing sprite to fail, output=%s", out)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(tmp, "seven-push-*")); len(leftovers) != 0 {
+		t.Fatalf("a failed push must not leave the host snapshot behind, found %q", leftovers)
+	}
+}
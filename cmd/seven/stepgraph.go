@@ -0,0 +1,196 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// initParallelism bounds how many independent init steps run at once. Each
+// one holds a sprite exec open, so the bound keeps a slow sprite API from
+// being flooded.
+const initParallelism = 4
+
+// graphStep is one node of a step graph: Run starts once every step named in
+// After has completed.
+type graphStep struct {
+	Name  string
+	After []string
+	Run   func(ctx context.Context, opts upOptions) error
+}
+
+// runStepGraph runs steps as their dependencies complete, at most
+// initParallelism at a time, and returns the names of the steps that
+// completed, in completion order. Once a step fails or ctx is cancelled no
+// further steps start, but those already running are waited for, so nothing
+// is left running when it returns. Each step logs through its own copy of
+// opts.Logger that attributes its lines to the step (see attributeStepLog);
+// the copies are serialized, so a Logger need not be safe for concurrent use.
+func runStepGraph(ctx context.Context, steps []graphStep, opts upOptions) ([]string, error) {
+	var logMu sync.Mutex
+	logger := opts.Logger
+	if logger == nil {
+		logger = func(string) {}
+	}
+	type stepResult struct {
+		name string
+		err  error
+	}
+	results := make(chan stepResult)
+	started := map[string]bool{}
+	done := map[string]bool{}
+	var completed []string
+	var errs []error
+	running := 0
+	for {
+		for _, step := range steps {
+			if len(errs) > 0 || ctx.Err() != nil || running >= initParallelism {
+				break
+			}
+			if started[step.Name] || !allStepsDone(step.After, done) {
+				continue
+			}
+			started[step.Name] = true
+			running++
+			stepOpts := opts
+			stepOpts.Logger = func(msg string) {
+				logMu.Lock()
+				defer logMu.Unlock()
+				logger(attributeStepLog(step.Name, msg))
+			}
+			go func(step graphStep, stepOpts upOptions) {
+				began := time.Now()
+				err := step.Run(ctx, stepOpts)
+				if err == nil {
+					// A step cut short by cancellation has not completed, even
+					// when it treats its own failures as non-fatal.
+					err = ctx.Err()
+				}
+				opts.Timings.record(step.Name, began, true)
+				results <- stepResult{name: step.Name, err: err}
+			}(step, stepOpts)
+		}
+		if running == 0 {
+			break
+		}
+		res := <-results
+		running--
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		done[res.name] = true
+		completed = append(completed, res.name)
+	}
+	if len(errs) == 0 && len(completed) < len(steps) {
+		for _, step := range steps {
+			if !done[step.Name] {
+				errs = append(errs, fmt.Errorf("step %s depends on a step that is not in the graph", step.Name))
+				break
+			}
+		}
+	}
+	return completed, errors.Join(errs...)
+}
+
+func allStepsDone(names []string, done map[string]bool) bool {
+	for _, name := range names {
+		if !done[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeStepLog tags a "[seven init] msg" line with the step that logged
+// it, as "[seven init:identity] msg", so interleaved lines from concurrent
+// steps stay readable. Lines without a bracketed prefix are left alone.
+func attributeStepLog(step, msg string) string {
+	if !strings.HasPrefix(msg, "[") {
+		return msg
+	}
+	idx := strings.Index(msg, "]")
+	if idx == -1 {
+		return msg
+	}
+	return msg[:idx] + ":" + step + msg[idx:]
+}
+
+// stepTimings collects how long each init step took for --timings. A nil
+// *stepTimings records nothing, so callers need not check whether timing was
+// requested.
+type stepTimings struct {
+	mu      sync.Mutex
+	start   time.Time
+	entries []stepTiming
+}
+
+type stepTiming struct {
+	Step     string
+	Start    time.Duration // offset from the start of the run
+	Duration time.Duration
+	Parallel bool // ran inside a step graph
+}
+
+func newStepTimings() *stepTimings {
+	return &stepTimings{start: time.Now()}
+}
+
+func (t *stepTimings) record(step string, started time.Time, parallel bool) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, stepTiming{
+		Step:     step,
+		Start:    started.Sub(t.start),
+		Duration: time.Since(started),
+		Parallel: parallel,
+	})
+}
+
+// write prints one line per step in start order, then what running the graph
+// steps concurrently saved over running them one after another, then the
+// total wall-clock time of the run.
+func (t *stepTimings) write(w io.Writer, phase string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := append([]stepTiming(nil), t.entries...)
+	total := time.Since(t.start)
+	slices.SortStableFunc(entries, func(a, b stepTiming) int { return cmp.Compare(a.Start, b.Start) })
+
+	var graphStart, graphEnd, graphSum time.Duration
+	graphSteps := 0
+	for _, e := range entries {
+		fmt.Fprintln(w, formatStyledBulletLog(fmt.Sprintf("[%s timings] %-20s %s", phase, e.Step, formatTiming(e.Duration))))
+		if !e.Parallel {
+			continue
+		}
+		if graphSteps == 0 || e.Start < graphStart {
+			graphStart = e.Start
+		}
+		graphEnd = max(graphEnd, e.Start+e.Duration)
+		graphSum += e.Duration
+		graphSteps++
+	}
+	if graphSteps > 0 {
+		wall := graphEnd - graphStart
+		fmt.Fprintln(w, formatStyledBulletLog(fmt.Sprintf("[%s timings] %d concurrent steps took %s wall-clock for %s of work (%s saved)",
+			phase, graphSteps, formatTiming(wall), formatTiming(graphSum), formatTiming(max(graphSum-wall, 0)))))
+	}
+	fmt.Fprintln(w, formatStyledBulletLog(fmt.Sprintf("[%s timings] total %s", phase, formatTiming(total))))
+}
+
+func formatTiming(d time.Duration) string {
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"seven/backend/fake"
+)
+
+func TestRunStepGraphBoundsParallelismAndOrdersDependents(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	var finished []string
+	step := func(name string, after ...string) graphStep {
+		return graphStep{Name: name, After: after, Run: func(ctx context.Context, opts upOptions) error {
+			mu.Lock()
+			active++
+			peak = max(peak, active)
+			for _, dep := range after {
+				if !slices.Contains(finished, dep) {
+					t.Errorf("%s started before its dependency %s finished", name, dep)
+				}
+			}
+			mu.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			active--
+			finished = append(finished, name)
+			mu.Unlock()
+			return nil
+		}}
+	}
+	steps := []graphStep{step("a"), step("b"), step("c"), step("d"), step("e"), step("f"), step("g", "a", "f")}
+
+	completed, err := runStepGraph(context.Background(), steps, upOptions{})
+	if err != nil {
+		t.Fatalf("runStepGraph failed: %v", err)
+	}
+	if len(completed) != len(steps) || completed[len(completed)-1] != "g" {
+		t.Fatalf("unexpected completion order: %q", completed)
+	}
+	if peak < 2 || peak > initParallelism {
+		t.Fatalf("expected between 2 and %d steps at once, got %d", initParallelism, peak)
+	}
+}
+
+func TestRunStepGraphSkipsDependentsOfFailedStep(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	step := func(name string, err error, after ...string) graphStep {
+		return graphStep{Name: name, After: after, Run: func(ctx context.Context, opts upOptions) error {
+			mu.Lock()
+			ran = append(ran, name)
+			mu.Unlock()
+			return err
+		}}
+	}
+	failure := errors.New("boom")
+	completed, err := runStepGraph(context.Background(), []graphStep{
+		step("identity", failure),
+		step("gh-auth", nil, "identity"),
+		step("codex-auth", nil),
+	}, upOptions{})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected the step's error, got %v", err)
+	}
+	if slices.Contains(ran, "gh-auth") || slices.Contains(completed, "identity") {
+		t.Fatalf("expected the failed step's dependent to be skipped, ran %q, completed %q", ran, completed)
+	}
+}
+
+func TestRunStepGraphDoesNotCompleteCancelledSteps(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	completed, err := runStepGraph(ctx, []graphStep{
+		{Name: "a", Run: func(ctx context.Context, opts upOptions) error { cancel(); return nil }},
+		{Name: "b", After: []string{"a"}, Run: func(ctx context.Context, opts upOptions) error {
+			t.Error("expected no step to start after cancellation")
+			return nil
+		}},
+	}, upOptions{})
+	if !errors.Is(err, context.Canceled) || len(completed) != 0 {
+		t.Fatalf("expected a cancelled graph with nothing completed, got %q, %v", completed, err)
+	}
+}
+
+func TestRunStepGraphReportsUnknownDependency(t *testing.T) {
+	_, err := runStepGraph(context.Background(), []graphStep{
+		{Name: "a", After: []string{"missing"}, Run: func(ctx context.Context, opts upOptions) error { return nil }},
+	}, upOptions{})
+	if err == nil || !strings.Contains(err.Error(), "step a depends on a step that is not in the graph") {
+		t.Fatalf("expected an unknown dependency error, got %v", err)
+	}
+}
+
+func TestAttributeStepLog(t *testing.T) {
+	for _, tc := range []struct{ msg, want string }{
+		{"[seven init] syncing codex auth into sprite", "[seven init:codex-auth] syncing codex auth into sprite"},
+		{"[seven up] x", "[seven up:codex-auth] x"},
+		{"plain output", "plain output"},
+		{"[unterminated", "[unterminated"},
+	} {
+		if got := attributeStepLog("codex-auth", tc.msg); got != tc.want {
+			t.Errorf("attributeStepLog(%q) = %q, want %q", tc.msg, got, tc.want)
+		}
+	}
+}
+
+func TestStepTimingsReportsConcurrentSavings(t *testing.T) {
+	previous := styleEnabled
+	styleEnabled = false
+	t.Cleanup(func() { styleEnabled = previous })
+	timings := &stepTimings{start: time.Now().Add(-5 * time.Second), entries: []stepTiming{
+		{Step: "cloned", Start: 3 * time.Second, Duration: 2 * time.Second},
+		{Step: "identity", Start: time.Second, Duration: 500 * time.Millisecond, Parallel: true},
+		{Step: "codex-auth", Start: time.Second, Duration: time.Second, Parallel: true},
+		{Step: "gh-auth", Start: 1500 * time.Millisecond, Duration: time.Second, Parallel: true},
+	}}
+	var out bytes.Buffer
+	timings.write(&out, "seven init")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+	for i, step := range []string{"identity", "codex-auth", "gh-auth", "cloned"} {
+		if !strings.HasPrefix(lines[i], "[seven init timings] "+step+" ") {
+			t.Fatalf("expected %s on line %d, got:\n%s", step, i, out.String())
+		}
+	}
+	if want := "[seven init timings] 3 concurrent steps took 1.50s wall-clock for 2.50s of work (1.00s saved)"; lines[4] != want {
+		t.Fatalf("unexpected savings line %q", lines[4])
+	}
+	if !strings.HasPrefix(lines[5], "[seven init timings] total 5.") {
+		t.Fatalf("unexpected total line %q", lines[5])
+	}
+
+	var nilTimings *stepTimings
+	nilTimings.record("identity", time.Now(), true)
+	nilTimings.write(&out, "seven init")
+}
+
+func TestRunInitRunsSetupStepsAsGraph(t *testing.T) {
+	repo := createTempRepo(t)
+	t.Chdir(repo)
+	isolateHostForInit(t)
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b := useFakeBackend(t)
+	b.OnExec(fake.CommandContains("printf 'present'"), fake.Reply{Stdout: "absent"})
+
+	var mu sync.Mutex
+	var logs []string
+	timings := newStepTimings()
+	opts := upOptions{AssumeLoggedIn: true, QuietExternal: true, Timings: timings, Logger: func(msg string) {
+		mu.Lock()
+		defer mu.Unlock()
+		logs = append(logs, msg)
+	}}
+	if _, err := runInit(context.Background(), opts); err != nil {
+		t.Fatalf("runInit failed: %v\nops: %q", err, b.Ops())
+	}
+
+	joined := strings.Join(logs, "\n")
+	if !strings.Contains(joined, "[seven init:assistant-sync] syncing claude auth into sprite") {
+		t.Fatalf("expected assistant sync log lines to name their step, got:\n%s", joined)
+	}
+	var out bytes.Buffer
+	timings.write(&out, "seven init")
+	for _, want := range []string{"identity", "gh-auth", "assistant-sync", "cloned", "concurrent steps took", "total"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in the timings report, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// syncAuthPhase prefixes sync-auth's log lines, as "[seven init]" does init's.
+const syncAuthPhase = "[seven sync-auth]"
+
+func cmdSyncAuth(args []string) {
+	fs := flag.NewFlagSet("sync-auth", flag.ExitOnError)
+	spriteName := fs.String("sprite", "", "refresh a specific sprite name")
+	all := fs.Bool("all", false, "refresh every sprite in this repo's family")
+
+	ordinal, args := parseSpriteOrdinal("sync-auth", args)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "seven sync-auth failed: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+	if *all && (ordinal > 0 || strings.TrimSpace(*spriteName) != "") {
+		fmt.Fprintln(os.Stderr, "seven sync-auth failed: --all cannot be combined with a sprite number or --sprite")
+		os.Exit(1)
+	}
+	if ordinal > 0 && strings.TrimSpace(*spriteName) != "" {
+		fmt.Fprintln(os.Stderr, "seven sync-auth failed: sprite number cannot be combined with --sprite")
+		os.Exit(1)
+	}
+
+	projectCfg, err := loadProjectConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven sync-auth failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	var names []string
+	if *all {
+		names = resolveFamilySprites(ctx, "sync-auth")
+	} else {
+		names = []string{resolveExistingSprite(ctx, "sync-auth", upOptions{SpriteName: strings.TrimSpace(*spriteName), SiblingOrdinal: ordinal})}
+	}
+
+	reports, err := runSyncAuth(ctx, names, upOptions{
+		Logger: func(msg string) { fmt.Println(formatStyledBulletLog(msg)) },
+		// Several sprites sync at once; their command output would interleave.
+		QuietExternal:      true,
+		SkipCredentialSync: activeUserConfig.Settings.skippedSync(),
+		ClaudeOwnLogin:     activeUserConfig.Settings.ClaudeLogin == "sprite",
+		RequiredMCPServers: projectCfg.MCP.Required,
+	})
+	writeSyncAuthReports(os.Stdout, reports)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven sync-auth failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// resolveFamilySprites lists the existing sprites of this repo's family,
+// exiting when there are none.
+func resolveFamilySprites(ctx context.Context, command string) []string {
+	info, err := resolveSpriteName()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve sprite name: %v\n", err)
+		os.Exit(1)
+	}
+	if err := prepareBackend(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	listOut, err := spriteList(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sprite list failed: %v\n", err)
+		os.Exit(1)
+	}
+	base := familyBase(info)
+	members := spriteFamilyMembers(base, listOut)
+	if len(members) == 0 {
+		fmt.Fprintf(os.Stderr, "seven %s failed: no sprites in the %s family (run 'seven up' first)\n", command, base)
+		os.Exit(1)
+	}
+	return members
+}
+
+// syncAuthReport is what sync-auth did to one sprite, one result per
+// assistant plus gh.
+type syncAuthReport struct {
+	Sprite  string
+	Results []syncAuthResult
+}
+
+type syncAuthResult struct {
+	Name   string // an assistant (see syncableAssistants), "mcp", or "gh"
+	Status string
+	Failed bool
+}
+
+func (r syncAuthReport) failed() bool {
+	return slices.ContainsFunc(r.Results, func(res syncAuthResult) bool { return res.Failed })
+}
+
+// runSyncAuth copies the host's current assistant credentials, MCP servers,
+// and gh token into each sprite, as init does at creation, and then checks
+// that Claude Code is logged in. The host is read once; the sprites are refreshed
+// concurrently. It fails when any sprite ends up with a failed result.
+func runSyncAuth(ctx context.Context, names []string, opts upOptions) ([]syncAuthReport, error) {
+	// detectHostAssistantState's own lines describe an init.
+	quiet := opts
+	quiet.Logger = func(string) {}
+	state := skipExcludedCredentialSync(detectHostAssistantState(ctx, quiet), syncAuthPhase, opts)
+	files, cleanup := hostAssistantSyncFiles(state, syncAuthPhase, opts)
+	defer cleanup()
+	ghToken := hostGhToken(ctx)
+
+	reports := make([]syncAuthReport, len(names))
+	steps := make([]graphStep, len(names))
+	for i, name := range names {
+		steps[i] = graphStep{Name: name, Run: func(ctx context.Context, opts upOptions) error {
+			reports[i] = syncAuthSprite(ctx, name, files, ghToken, opts)
+			return nil
+		}}
+	}
+	if _, err := runStepGraph(ctx, steps, opts); err != nil {
+		return reports, err
+	}
+	failed := 0
+	for _, report := range reports {
+		if report.failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return reports, fmt.Errorf("%d of %d sprites were not fully refreshed", failed, len(reports))
+	}
+	return reports, nil
+}
+
+func syncAuthSprite(ctx context.Context, spriteName string, files []syncFile, ghToken string, opts upOptions) syncAuthReport {
+	report := syncAuthReport{Sprite: spriteName}
+	installed, syncErr := syncHostFilesToSprite(ctx, spriteName, files, syncAuthPhase, opts)
+	for _, assistant := range syncableAssistants {
+		res := syncAuthResult{Name: assistant}
+		res.Status, res.Failed = assistantSyncResult(assistant, files, installed, syncErr, opts.SkipCredentialSync)
+		if assistant == "claude" {
+			synced := syncFileParts(installed, "claude")
+			res = verifyClaudeAfterSync(ctx, spriteName, res, slices.Contains(synced, "auth") || slices.Contains(synced, "credentials"))
+		}
+		report.Results = append(report.Results, res)
+	}
+
+	mcp := syncAuthResult{Name: "mcp"}
+	outcomes, err := syncMCPServers(ctx, spriteName, syncAuthPhase, opts)
+	mcp.Status = mcpSyncResult(outcomes)
+	if err != nil {
+		mcp.Status, mcp.Failed = fmt.Sprintf("%s; failed: %v", mcp.Status, err), true
+	}
+	report.Results = append(report.Results, mcp)
+
+	gh := syncAuthResult{Name: "gh", Status: "synced token"}
+	if ghToken == "" {
+		gh.Status = "nothing to sync (gh is not logged in on the host)"
+	} else if err := ensureGhAuthInSprite(ctx, spriteName, ghToken, syncAuthPhase, opts); err != nil {
+		gh.Status, gh.Failed = fmt.Sprintf("failed: %v", err), true
+	}
+	report.Results = append(report.Results, gh)
+	return report
+}
+
+// assistantSyncResult summarizes what happened to one assistant's planned
+// files in a sprite.
+func assistantSyncResult(assistant string, planned, installed []syncFile, syncErr error, excluded []string) (string, bool) {
+	if slices.Contains(excluded, assistant) {
+		return "skipped (excluded by the user profile)", false
+	}
+	wanted := syncFileParts(planned, assistant)
+	if len(wanted) == 0 {
+		return fmt.Sprintf("nothing to sync (no %s config or credentials on the host)", assistant), false
+	}
+	if syncErr != nil {
+		return fmt.Sprintf("failed: %v", syncErr), true
+	}
+	got := syncFileParts(installed, assistant)
+	switch {
+	case len(got) == 0:
+		return fmt.Sprintf("skipped (%s not found in sprite)", assistantCommands[assistant]), false
+	case len(got) < len(wanted):
+		var missed []string
+		for _, part := range wanted {
+			if !slices.Contains(got, part) {
+				missed = append(missed, part)
+			}
+		}
+		return fmt.Sprintf("synced %s; %s failed (see log)", strings.Join(got, ", "), strings.Join(missed, ", ")), true
+	}
+	return "synced " + strings.Join(got, ", "), false
+}
+
+// mcpSyncResult summarizes the MCP servers that are live in a sprite and the
+// ones that were dropped.
+func mcpSyncResult(outcomes []mcpOutcome) string {
+	if len(outcomes) == 0 {
+		return "nothing to sync (no MCP servers on the host)"
+	}
+	var live, dropped []string
+	for _, o := range outcomes {
+		if o.live() {
+			live = append(live, o.Server.Name)
+		} else {
+			dropped = append(dropped, fmt.Sprintf("%s (%s)", o.Server.Name, o.Dropped))
+		}
+	}
+	status := "live: none"
+	if len(live) > 0 {
+		status = "live: " + strings.Join(live, ", ")
+	}
+	if len(dropped) > 0 {
+		status += "; dropped: " + strings.Join(dropped, ", ")
+	}
+	return status
+}
+
+// syncFileParts names the files of one assistant by their label without the
+// assistant, e.g. "config" for "claude config".
+func syncFileParts(files []syncFile, assistant string) []string {
+	var parts []string
+	for _, f := range files {
+		if f.Assistant == assistant {
+			parts = append(parts, strings.TrimPrefix(f.Label, assistant+" "))
+		}
+	}
+	return parts
+}
+
+// verifyClaudeAfterSync appends Claude Code's login state to its result.
+// Credentials that were synced but leave claude logged out are a failure:
+// the host token has probably been refreshed since and invalidated the copy.
+func verifyClaudeAfterSync(ctx context.Context, spriteName string, res syncAuthResult, credentialsSynced bool) syncAuthResult {
+	if res.Failed || strings.HasPrefix(res.Status, "skipped") {
+		return res
+	}
+	status, err := spriteClaudeAuthStatus(ctx, spriteName)
+	switch {
+	case err != nil:
+		res.Status, res.Failed = fmt.Sprintf("%s; could not check login: %v", res.Status, err), true
+	case status.LoggedIn:
+		res.Status += "; logged in"
+	case status.TokenReused:
+		res.Status, res.Failed = res.Status+"; refresh token already used by another copy (run `seven up --claude-login` to give the sprite its own login)", true
+	case credentialsSynced:
+		res.Status, res.Failed = res.Status+"; still logged out (run `claude` inside the sprite to log in)", true
+	default:
+		res.Status += "; logged out"
+	}
+	return res
+}
+
+func writeSyncAuthReports(w io.Writer, reports []syncAuthReport) {
+	for _, report := range reports {
+		if report.Sprite == "" {
+			continue // never started: interrupted first
+		}
+		fmt.Fprintf(w, "%s:\n", report.Sprite)
+		for _, res := range report.Results {
+			fmt.Fprintf(w, "  %-7s %s\n", res.Name, res.Status)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"seven/backend/fake"
+)
+
+func TestRunSyncAuthReportsPerAssistantAndVerifiesClaude(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("Linux credential-file path; macOS uses the keychain")
+	}
+	isolateHostForInit(t)
+	// A host claude that is logged out keeps ~/.claude.json out of the plan.
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "claude"), []byte("#!/bin/sh\necho '{\"loggedIn\":false}'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	home, _ := os.UserHomeDir()
+	if err := os.MkdirAll(filepath.Join(home, ".claude"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".claude", ".credentials.json"), []byte(`{"claudeAiOauth":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	b := useFakeBackend(t)
+	b.AddSprite("hello", "hello-02")
+	b.OnExec(func(call fake.ExecCall) bool {
+		return call.Sprite == "hello" && strings.Contains(call.String(), "claude auth status")
+	}, fake.Reply{Stdout: `{"loggedIn":true}`})
+	b.OnExec(fake.CommandContains("claude auth status"), fake.Reply{Stdout: `{"loggedIn":false}`})
+
+	var logs []string
+	var out bytes.Buffer
+	reports, err := runSyncAuth(context.Background(), []string{"hello", "hello-02"}, upOptions{
+		QuietExternal:      true,
+		SkipCredentialSync: []string{"codex"},
+		Logger:             func(msg string) { logs = append(logs, msg) },
+	})
+	writeSyncAuthReports(&out, reports)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 sprites were not fully refreshed") {
+		t.Fatalf("expected hello-02 to fail verification, got %v\n%s", err, out.String())
+	}
+	want := `hello:
+  claude  synced credentials; logged in
+  codex   skipped (excluded by the user profile)
+  cursor  nothing to sync (no cursor config or credentials on the host)
+  gemini  nothing to sync (no gemini config or credentials on the host)
+  mcp     nothing to sync (no MCP servers on the host)
+  gh      nothing to sync (gh is not logged in on the host)
+hello-02:
+  claude  synced credentials; still logged out (run ` + "`claude`" + ` inside the sprite to log in)
+  codex   skipped (excluded by the user profile)
+  cursor  nothing to sync (no cursor config or credentials on the host)
+  gemini  nothing to sync (no gemini config or credentials on the host)
+  mcp     nothing to sync (no MCP servers on the host)
+  gh      nothing to sync (gh is not logged in on the host)
+`
+	if out.String() != want {
+		t.Fatalf("unexpected report:\n%s\nwant:\n%s", out.String(), want)
+	}
+	for _, name := range []string{"hello", "hello-02"} {
+		sprite, _ := b.Sprite(name)
+		if _, ok := sprite.Files["/tmp/host-claude-credentials.json"]; !ok {
+			t.Fatalf("expected the credentials upload into %s, got %v", name, sprite.Files)
+		}
+	}
+	if joined := strings.Join(logs, "\n"); !strings.Contains(joined, "[seven sync-auth:hello-02] syncing claude credentials into sprite") {
+		t.Fatalf("expected per-sprite log lines, got:\n%s", joined)
+	}
+}
+
+func TestAssistantSyncResult(t *testing.T) {
+	planned := []syncFile{
+		{Label: "claude config", Assistant: "claude", Tool: "claude"},
+		{Label: "claude auth", Assistant: "claude", Tool: "claude"},
+		{Label: "cursor auth", Assistant: "cursor", Tool: "cursor-agent"},
+	}
+	cases := map[string]struct {
+		installed []syncFile
+		assistant string
+		want      string
+		failed    bool
+	}{
+		"all synced":      {installed: planned, assistant: "claude", want: "synced config, auth"},
+		"partly synced":   {installed: planned[1:], assistant: "claude", want: "synced auth; config failed (see log)", failed: true},
+		"tool missing":    {assistant: "claude", want: "skipped (claude not found in sprite)"},
+		"nothing on host": {assistant: "codex", want: "nothing to sync (no codex config or credentials on the host)"},
+		"command missing": {installed: planned[:2], assistant: "cursor", want: "skipped (cursor-agent not found in sprite)"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, failed := assistantSyncResult(tc.assistant, planned, tc.installed, nil, nil)
+			if got != tc.want || failed != tc.failed {
+				t.Fatalf("got %q, %v; want %q, %v", got, failed, tc.want, tc.failed)
+			}
+		})
+	}
+}
+
+func TestSevenSyncAuthRejectsConflictingTargets(t *testing.T) {
+	for _, args := range [][]string{{"sync-auth", "2", "--all"}, {"sync-auth", "--all", "--sprite", "x"}, {"sync-auth", "2", "--sprite", "x"}} {
+		out, err := exec.Command(testSevenBin, args...).CombinedOutput()
+		if err == nil || !strings.Contains(string(out), "seven sync-auth failed: ") {
+			t.Fatalf("expected %q to be rejected, got %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestVerifyClaudeAfterSyncReportsReusedToken(t *testing.T) {
+	b := useFakeBackend(t)
+	b.AddSprite("hello")
+	b.OnExec(fake.CommandContains("claude auth status"), fake.Reply{Stdout: `{"loggedIn":false,"error":"Refresh token has already been used"}`})
+
+	res := verifyClaudeAfterSync(context.Background(), "hello", syncAuthResult{Name: "claude", Status: "synced credentials"}, true)
+	if !res.Failed || !strings.Contains(res.Status, "run `seven up --claude-login`") {
+		t.Fatalf("expected a failed result pointing at --claude-login, got %+v", res)
+	}
+}
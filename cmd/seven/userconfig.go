@@ -0,0 +1,366 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// syncableAssistants are the assistants whose host credentials seven copies
+// into a new sprite, in the order syncHostAssistantState handles them.
+var syncableAssistants = []string{"claude", "codex", "cursor", "gemini"}
+
+var spriteOrgPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// userConfig is the personal, machine-wide configuration read from
+// ~/.config/seven/config.toml. Top-level keys apply to every profile; a
+// [profile.<name>] section overrides them when that profile is selected:
+//
+//	profile = "work"        # used when neither --profile nor SEVEN_PROFILE is set
+//	backend = "sprite"       # or "docker" / "podman"
+//	container_image = "..."  # docker/podman only; default builds seven's own
+//	upgrade_sprite_cli = true
+//
+//	[profile.work]
+//	org = "acme"
+//	assistant = "claude"
+//	sync = ["claude"]
+//	claude_login = "sprite"  # sprites log Claude in themselves
+//
+//	[profile.oss]
+//	sync = []
+type userConfig struct {
+	Path          string // file that was read; empty when there is none
+	Profile       string // selected profile; empty for the top-level settings only
+	ProfileSource string // "--profile", "SEVEN_PROFILE", or the config file
+	Profiles      []string
+	Settings      userSettings
+}
+
+type userSettings struct {
+	Backend          string   // sandbox provider, one of backendNames
+	ContainerImage   string   // image for the docker and podman backends; empty builds seven's own
+	Assistant        string   // default assistant after --assistant and .seven.toml
+	Org              string   // sprite org passed to every sprite CLI call
+	Sync             []string // assistants whose host credentials are copied
+	ClaudeLogin      string   // "host" copies the host's Claude login; "sprite" logs each sprite in on its own
+	UpgradeSpriteCLI bool     // whether seven up checks for sprite CLI updates
+}
+
+// skippedSync lists the syncable assistants the profile leaves out, so the
+// zero value of upOptions keeps syncing everything.
+func (s userSettings) skippedSync() []string {
+	var skipped []string
+	for _, name := range syncableAssistants {
+		if !slices.Contains(s.Sync, name) {
+			skipped = append(skipped, name)
+		}
+	}
+	return skipped
+}
+
+// activeUserConfig is loaded once in main before any command runs.
+var activeUserConfig = userConfig{Settings: defaultUserSettings()}
+
+func defaultUserSettings() userSettings {
+	return userSettings{
+		Backend:          "sprite",
+		Sync:             append([]string(nil), syncableAssistants...),
+		ClaudeLogin:      "host",
+		UpgradeSpriteCLI: true,
+	}
+}
+
+// userConfigPath honours XDG_CONFIG_HOME and otherwise uses ~/.config on every
+// platform, so the documented path is the real one on macOS too.
+func userConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "seven", "config.toml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "seven", "config.toml"), nil
+}
+
+// selectUserProfile strips --profile from a command's arguments, loads the
+// user config for the chosen profile, and makes it active. Arguments after a
+// "--" terminator belong to the command being run and are left alone.
+func selectUserProfile(command string, args []string) []string {
+	profile, args, err := extractProfileFlag(args)
+	if err == nil {
+		activeUserConfig, err = loadUserConfig(profile)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seven %s failed: %v\n", command, err)
+		os.Exit(1)
+	}
+	spriteOrg = activeUserConfig.Settings.Org
+	if activeBackend, err = newBackend(activeUserConfig.Settings); err != nil {
+		fmt.Fprintf(os.Stderr, "seven %s failed: %v\n", command, err)
+		os.Exit(1)
+	}
+	return args
+}
+
+func extractProfileFlag(args []string) (string, []string, error) {
+	profile := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return profile, append(rest, args[i:]...), nil
+		case arg == "--profile" || arg == "-profile":
+			if i+1 >= len(args) {
+				return "", nil, errors.New("--profile requires a profile name")
+			}
+			i++
+			profile = args[i]
+		case strings.HasPrefix(arg, "--profile=") || strings.HasPrefix(arg, "-profile="):
+			_, profile, _ = strings.Cut(arg, "=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return profile, rest, nil
+}
+
+// loadUserConfig reads the user config and selects a profile: the flag value
+// wins over SEVEN_PROFILE, which wins over the file's top-level profile key.
+// Naming a profile the file does not define is an error.
+func loadUserConfig(flagProfile string) (userConfig, error) {
+	path, err := userConfigPath()
+	if err != nil {
+		return userConfig{}, err
+	}
+	contents := ""
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		contents = string(data)
+	case errors.Is(err, os.ErrNotExist):
+		path = ""
+	default:
+		return userConfig{}, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	profile, source := strings.TrimSpace(flagProfile), "--profile"
+	if profile == "" {
+		profile, source = strings.TrimSpace(os.Getenv("SEVEN_PROFILE")), "SEVEN_PROFILE"
+	}
+	config, err := parseUserConfig(path, contents, profile)
+	if err != nil {
+		return userConfig{}, err
+	}
+	if profile != "" {
+		config.ProfileSource = source
+	}
+	// SEVEN_BACKEND overrides the profile, so CI and the integration tests can
+	// pick a backend without writing a config file.
+	if name := strings.TrimSpace(os.Getenv("SEVEN_BACKEND")); name != "" {
+		if !slices.Contains(backendNames, name) {
+			return userConfig{}, fmt.Errorf("SEVEN_BACKEND must be one of %s, got %q", strings.Join(backendNames, ", "), name)
+		}
+		config.Settings.Backend = name
+	}
+	return config, nil
+}
+
+// parseUserConfig validates every profile, not only the selected one, so a
+// mistake is reported the first time seven runs rather than the first time
+// that profile is used.
+func parseUserConfig(path, contents, profile string) (userConfig, error) {
+	label := path
+	if label == "" {
+		label = "config.toml"
+	}
+	doc, err := parseConfigDocument(label, contents)
+	if err != nil {
+		return userConfig{}, err
+	}
+	config := userConfig{Path: path}
+	for _, header := range doc.sections {
+		name, ok := strings.CutPrefix(header.section, "profile.")
+		if !ok || strings.Contains(name, ".") {
+			return userConfig{}, fmt.Errorf("invalid %s line %d: unknown section [%s] (use [profile.<name>])", label, header.value.line, header.section)
+		}
+		config.Profiles = append(config.Profiles, name)
+	}
+
+	defaultProfile := ""
+	scratch := defaultUserSettings()
+	for _, entry := range doc.entries {
+		if entry.section == "" && entry.key == "profile" {
+			if defaultProfile, err = entry.value.asString(label, entry.key); err != nil {
+				return userConfig{}, err
+			}
+			continue
+		}
+		if err := scratch.apply(label, doc, entry); err != nil {
+			return userConfig{}, err
+		}
+	}
+	if profile == "" && defaultProfile != "" {
+		profile = defaultProfile
+		config.ProfileSource = label
+		if !slices.Contains(config.Profiles, profile) {
+			return userConfig{}, fmt.Errorf("invalid %s: default profile %q has no [profile.%s] section", label, profile, profile)
+		}
+	}
+	if profile != "" && !slices.Contains(config.Profiles, profile) {
+		if path == "" {
+			return userConfig{}, fmt.Errorf("profile %q selected but no user config exists (create %s)", profile, mustUserConfigPath())
+		}
+		return userConfig{}, fmt.Errorf("profile %q is not defined in %s (defined: %s)", profile, label, profileList(config.Profiles))
+	}
+	config.Profile = profile
+
+	// Top-level settings first, then the selected profile's overrides.
+	config.Settings = defaultUserSettings()
+	sections := []string{""}
+	if profile != "" {
+		sections = append(sections, "profile."+profile)
+	}
+	for _, section := range sections {
+		for _, entry := range doc.entries {
+			if entry.section != section || (section == "" && entry.key == "profile") {
+				continue
+			}
+			if err := config.Settings.apply(label, doc, entry); err != nil {
+				return userConfig{}, err
+			}
+		}
+	}
+	return config, nil
+}
+
+func (s *userSettings) apply(file string, doc configDocument, entry configEntry) error {
+	var err error
+	value := entry.value
+	switch entry.key {
+	case "backend":
+		s.Backend, err = value.asString(file, entry.key)
+		if err == nil && !slices.Contains(backendNames, s.Backend) {
+			err = fmt.Errorf("invalid %s line %d: backend must be one of %s", file, value.line, strings.Join(backendNames, ", "))
+		}
+	case "container_image":
+		s.ContainerImage, err = value.asString(file, entry.key)
+		if err == nil && (s.ContainerImage == "" || strings.ContainsAny(s.ContainerImage, " \t")) {
+			err = fmt.Errorf("invalid %s line %d: container_image must be a non-empty image reference", file, value.line)
+		}
+	case "assistant":
+		s.Assistant, err = value.asAssistant(file, entry.key)
+	case "org":
+		s.Org, err = value.asString(file, entry.key)
+		if err == nil && !spriteOrgPattern.MatchString(s.Org) {
+			err = fmt.Errorf("invalid %s line %d: org %q is not a valid sprite org name", file, value.line, s.Org)
+		}
+	case "sync":
+		s.Sync, err = value.asSyncList(file, entry.key)
+	case "claude_login":
+		s.ClaudeLogin, err = value.asString(file, entry.key)
+		if err == nil && s.ClaudeLogin != "host" && s.ClaudeLogin != "sprite" {
+			err = fmt.Errorf("invalid %s line %d: claude_login must be \"host\" or \"sprite\"", file, value.line)
+		}
+	case "upgrade_sprite_cli":
+		s.UpgradeSpriteCLI, err = value.asBool(file, entry.key)
+	default:
+		err = doc.unknownKey(file, entry)
+	}
+	return err
+}
+
+// asSyncList accepts an array of syncable assistant names; [] syncs nothing.
+func (v configValue) asSyncList(file, key string) ([]string, error) {
+	if v.kind != "array" {
+		return nil, fmt.Errorf("invalid %s line %d: %s must be an array of assistant names", file, v.line, key)
+	}
+	names := []string{}
+	for _, item := range v.list {
+		if item.kind != "string" || !slices.Contains(syncableAssistants, item.str) {
+			return nil, fmt.Errorf("invalid %s line %d: %s entries must be one of %s", file, v.line, key, strings.Join(syncableAssistants, ", "))
+		}
+		if slices.Contains(names, item.str) {
+			return nil, fmt.Errorf("invalid %s line %d: duplicate %s entry %q", file, v.line, key, item.str)
+		}
+		names = append(names, item.str)
+	}
+	return names, nil
+}
+
+func mustUserConfigPath() string {
+	path, err := userConfigPath()
+	if err != nil {
+		return "~/.config/seven/config.toml"
+	}
+	return path
+}
+
+func profileList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+func cmdConfig(args []string) {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "seven config failed: usage: seven config show [--profile name]")
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	_ = fs.Parse(args[1:])
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "seven config failed: unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		os.Exit(1)
+	}
+	fmt.Print(formatUserConfig(activeUserConfig))
+}
+
+// formatUserConfig renders the effective settings in config.toml syntax, with
+// where they came from as comments.
+func formatUserConfig(config userConfig) string {
+	var b strings.Builder
+	if config.Path == "" {
+		fmt.Fprintf(&b, "# %s not found; using defaults\n", mustUserConfigPath())
+	} else {
+		fmt.Fprintf(&b, "# %s\n", config.Path)
+	}
+	if config.Profile == "" {
+		fmt.Fprintf(&b, "# profile: none (defined: %s)\n", profileList(config.Profiles))
+	} else {
+		fmt.Fprintf(&b, "# profile: %s (from %s; defined: %s)\n", config.Profile, config.ProfileSource, profileList(config.Profiles))
+	}
+	s := config.Settings
+	fmt.Fprintf(&b, "backend = %q\n", s.Backend)
+	if s.ContainerImage != "" {
+		fmt.Fprintf(&b, "container_image = %q\n", s.ContainerImage)
+	} else if s.Backend != "sprite" {
+		fmt.Fprintf(&b, "# container_image: not set (seven builds %s)\n", defaultContainerImage())
+	}
+	if s.Assistant == "" {
+		b.WriteString("# assistant: not set (.seven.toml or host credentials decide)\n")
+	} else {
+		fmt.Fprintf(&b, "assistant = %q\n", s.Assistant)
+	}
+	if s.Org == "" {
+		b.WriteString("# org: not set (the sprite CLI's default org)\n")
+	} else {
+		fmt.Fprintf(&b, "org = %q\n", s.Org)
+	}
+	quoted := make([]string, 0, len(s.Sync))
+	for _, name := range s.Sync {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	fmt.Fprintf(&b, "sync = [%s]\n", strings.Join(quoted, ", "))
+	fmt.Fprintf(&b, "claude_login = %q\n", s.ClaudeLogin)
+	fmt.Fprintf(&b, "upgrade_sprite_cli = %t\n", s.UpgradeSpriteCLI)
+	return b.String()
+}
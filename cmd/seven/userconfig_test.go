@@ -0,0 +1,198 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testUserConfig = `# personal defaults
+profile = "work"
+upgrade_sprite_cli = false
+
+[profile.work]
+org = "acme"
+assistant = "claude"
+sync = ["claude"]
+
+[profile.oss]
+upgrade_sprite_cli = true
+sync = []
+`
+
+func TestParseUserConfigProfiles(t *testing.T) {
+	config, err := parseUserConfig("/cfg/config.toml", testUserConfig, "")
+	if err != nil {
+		t.Fatalf("expected valid user config: %v", err)
+	}
+	if config.Profile != "work" || config.ProfileSource != "/cfg/config.toml" {
+		t.Fatalf("expected the file's default profile, got %q from %q", config.Profile, config.ProfileSource)
+	}
+	if s := config.Settings; s.Org != "acme" || s.Assistant != "claude" || !slices.Equal(s.Sync, []string{"claude"}) || s.UpgradeSpriteCLI {
+		t.Fatalf("unexpected work settings: %+v", s)
+	}
+	if skipped := config.Settings.skippedSync(); !slices.Equal(skipped, []string{"codex", "cursor", "gemini"}) {
+		t.Fatalf("expected every other assistant to be skipped, got %v", skipped)
+	}
+
+	config, err = parseUserConfig("/cfg/config.toml", testUserConfig, "oss")
+	if err != nil {
+		t.Fatalf("expected oss profile: %v", err)
+	}
+	if s := config.Settings; s.Org != "" || s.Assistant != "" || len(s.Sync) != 0 || !s.UpgradeSpriteCLI {
+		t.Fatalf("unexpected oss settings: %+v", s)
+	}
+
+	config, err = parseUserConfig("", "", "")
+	if err != nil || config.Profile != "" || !slices.Equal(config.Settings.Sync, syncableAssistants) || !config.Settings.UpgradeSpriteCLI {
+		t.Fatalf("expected defaults without a config file, got %+v err=%v", config, err)
+	}
+}
+
+func TestParseUserConfigRejectsInvalidLines(t *testing.T) {
+	for name, tc := range map[string]struct {
+		contents string
+		profile  string
+		want     string
+	}{
+		"unknown section":      {"[work]\norg = \"acme\"\n", "", "line 1: unknown section [work]"},
+		"nested profile":       {"[profile.work.extra]\n", "", "line 1: unknown section [profile.work.extra]"},
+		"unknown key":          {"[profile.work]\nregion = \"ord\"\n", "", "line 2: unknown key \"region\" in [profile.work]"},
+		"profile in profile":   {"[profile.work]\nprofile = \"oss\"\n", "", "line 2: unknown key \"profile\""},
+		"unknown sync":         {"sync = [\"copilot\"]\n", "", "line 1: sync entries must be one of claude, codex, cursor, gemini"},
+		"invalid org":          {"[profile.oss]\norg = \"acme corp\"\n", "", "line 2: org \"acme corp\""},
+		"bad assistant":        {"assistant = \"gpt\"\n", "", "line 1: assistant must be"},
+		"unknown backend":      {"[profile.work]\nbackend = \"lxc\"\n", "", "line 2: backend must be one of sprite"},
+		"empty image":          {"container_image = \"\"\n", "", "line 1: container_image must be a non-empty image reference"},
+		"missing default":      {"profile = \"work\"\n", "", "default profile \"work\" has no [profile.work] section"},
+		"undefined selection":  {"[profile.work]\n", "oss", "profile \"oss\" is not defined in config.toml (defined: work)"},
+		"unused profile error": {"[profile.work]\n[profile.oss]\nsync = \"claude\"\n", "work", "line 3: sync must be an array"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseUserConfig("config.toml", tc.contents, tc.profile)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestExtractProfileFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		profile string
+		rest    []string
+	}{
+		{[]string{"2", "--profile", "work", "--no-console"}, "work", []string{"2", "--no-console"}},
+		{[]string{"--profile=oss"}, "oss", []string{}},
+		{[]string{"-profile", "oss", "--", "echo", "--profile", "x"}, "oss", []string{"--", "echo", "--profile", "x"}},
+	} {
+		profile, rest, err := extractProfileFlag(tc.args)
+		if err != nil || profile != tc.profile || !slices.Equal(rest, tc.rest) {
+			t.Fatalf("extractProfileFlag(%q) = %q, %q, %v", tc.args, profile, rest, err)
+		}
+	}
+	if _, _, err := extractProfileFlag([]string{"--profile"}); err == nil {
+		t.Fatal("expected --profile without a name to fail")
+	}
+}
+
+func writeUserConfig(t *testing.T, home, contents string) {
+	t.Helper()
+	dir := filepath.Join(home, ".config", "seven")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSevenConfigShow(t *testing.T) {
+	home := t.TempDir()
+	writeUserConfig(t, home, testUserConfig)
+	run := func(extraEnv []string, args ...string) (string, error) {
+		cmd := exec.Command(testSevenBin, append([]string{"config", "show"}, args...)...)
+		cmd.Dir = t.TempDir()
+		cmd.Env = append(os.Environ(), "HOME="+home, "XDG_CONFIG_HOME=", "SEVEN_PROFILE=")
+		cmd.Env = append(cmd.Env, extraEnv...)
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+
+	out, err := run(nil)
+	if err != nil {
+		t.Fatalf("seven config show failed: %v\n%s", err, out)
+	}
+	for _, want := range []string{"# profile: work (from " + filepath.Join(home, ".config", "seven", "config.toml"), "org = \"acme\"", "sync = [\"claude\"]", "upgrade_sprite_cli = false"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	out, err = run([]string{"SEVEN_PROFILE=oss"})
+	if err != nil || !strings.Contains(out, "# profile: oss (from SEVEN_PROFILE") || !strings.Contains(out, "sync = []") {
+		t.Fatalf("expected SEVEN_PROFILE to select oss, err=%v output:\n%s", err, out)
+	}
+
+	out, err = run([]string{"SEVEN_PROFILE=oss"}, "--profile", "work")
+	if err != nil || !strings.Contains(out, "# profile: work (from --profile") {
+		t.Fatalf("expected --profile to win over SEVEN_PROFILE, err=%v output:\n%s", err, out)
+	}
+
+	out, err = run(nil, "--profile", "personal")
+	if err == nil || !strings.Contains(out, "profile \"personal\" is not defined") {
+		t.Fatalf("expected an undefined profile to fail, err=%v output:\n%s", err, out)
+	}
+}
+
+func TestSevenUpAppliesUserProfile(t *testing.T) {
+	repo := createTempRepo(t)
+	state, logPath, cleanup := createFakeSprite(t)
+	defer cleanup()
+
+	if err := os.WriteFile(filepath.Join(repo, ".sprite"), []byte("hello\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .sprite: %v", err)
+	}
+	configHome := t.TempDir()
+	writeUserConfig(t, configHome, testUserConfig)
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, ".codex"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".codex", "config.toml"), []byte("model = \"o3\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(testSevenBin, "up", "--assume-logged-in", "--no-tui", "--no-console")
+	cmd.Dir = repo
+	cmd.Env = append(os.Environ(),
+		"HOME="+home,
+		"XDG_CONFIG_HOME="+filepath.Join(configHome, ".config"),
+		"SEVEN_PROFILE=",
+		"PATH="+filepath.Dir(state)+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"SPRITE_STATE="+state,
+		"SPRITE_LOG="+logPath,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("seven up failed: %v\n%s", err, out)
+	}
+	logData, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("expected sprite log: %v", err)
+	}
+	log := string(logData)
+	if !strings.Contains(log, "org acme\ncreate hello") {
+		t.Fatalf("expected sprite commands to target the profile's org, got: %s", log)
+	}
+	if strings.Contains(log, "upgrade --check") {
+		t.Fatalf("expected the profile to disable the sprite CLI update check, got: %s", log)
+	}
+	if strings.Contains(log, "host-codex-config.toml") || !strings.Contains(string(out), "not syncing codex credentials") {
+		t.Fatalf("expected codex credentials to stay on the host, output:\n%s\nlog: %s", out, log)
+	}
+}